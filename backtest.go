@@ -0,0 +1,452 @@
+// backtest.go
+// Historical replay of GenerateSignal against OHLCV bars, with fee/slippage
+// simulation and PnL reporting. Mirrors how BBGO strategies expose a
+// backtest: section alongside live sessions.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// =============================================================================
+// HISTORICAL DATA SOURCES
+// =============================================================================
+
+// Bar is a single OHLCV candle for a symbol.
+type Bar struct {
+	Symbol string
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// HistoricalDataSource loads historical bars for a symbol within a time range.
+type HistoricalDataSource interface {
+	LoadBars(symbol string, start, end time.Time, interval string) ([]Bar, error)
+}
+
+// CSVDataSource loads bars from <Dir>/<symbol>.csv with columns
+// time,open,high,low,close,volume (RFC3339 timestamps).
+type CSVDataSource struct {
+	Dir string
+}
+
+func (s *CSVDataSource) LoadBars(symbol string, start, end time.Time, interval string) ([]Bar, error) {
+	f, err := os.Open(filepath.Join(s.Dir, symbol+".csv"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var bars []Bar
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, row[0])
+		if err != nil || t.Before(start) || t.After(end) {
+			continue
+		}
+		open, _ := strconv.ParseFloat(row[1], 64)
+		high, _ := strconv.ParseFloat(row[2], 64)
+		low, _ := strconv.ParseFloat(row[3], 64)
+		cls, _ := strconv.ParseFloat(row[4], 64)
+		vol, _ := strconv.ParseFloat(row[5], 64)
+		bars = append(bars, Bar{Symbol: symbol, Time: t, Open: open, High: high, Low: low, Close: cls, Volume: vol})
+	}
+	return bars, nil
+}
+
+// YahooHistoricalDataSource loads bars via the Yahoo Finance chart API
+// (https://query1.finance.yahoo.com/v8/finance/chart/<symbol>?range=...&interval=...).
+type YahooHistoricalDataSource struct {
+	client *http.Client
+}
+
+func NewYahooHistoricalDataSource() *YahooHistoricalDataSource {
+	return &YahooHistoricalDataSource{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Open   []float64 `json:"open"`
+					High   []float64 `json:"high"`
+					Low    []float64 `json:"low"`
+					Close  []float64 `json:"close"`
+					Volume []float64 `json:"volume"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+	} `json:"chart"`
+}
+
+func (s *YahooHistoricalDataSource) LoadBars(symbol string, start, end time.Time, interval string) ([]Bar, error) {
+	rangeParam := yahooRangeFor(start, end)
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?range=%s&interval=%s", symbol, rangeParam, interval)
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var parsed yahooChartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Chart.Result) == 0 || len(parsed.Chart.Result[0].Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("backtest: no chart data returned for %s", symbol)
+	}
+
+	result := parsed.Chart.Result[0]
+	quote := result.Indicators.Quote[0]
+
+	var bars []Bar
+	for i, ts := range result.Timestamp {
+		t := time.Unix(ts, 0)
+		if t.Before(start) || t.After(end) {
+			continue
+		}
+		bars = append(bars, Bar{
+			Symbol: symbol,
+			Time:   t,
+			Open:   quote.Open[i],
+			High:   quote.High[i],
+			Low:    quote.Low[i],
+			Close:  quote.Close[i],
+			Volume: quote.Volume[i],
+		})
+	}
+	return bars, nil
+}
+
+// yahooRangeFor picks the smallest Yahoo range= value that covers [start, end].
+func yahooRangeFor(start, end time.Time) string {
+	days := end.Sub(start).Hours() / 24
+	switch {
+	case days <= 5:
+		return "5d"
+	case days <= 30:
+		return "1mo"
+	case days <= 90:
+		return "3mo"
+	case days <= 365:
+		return "1y"
+	default:
+		return "5y"
+	}
+}
+
+// =============================================================================
+// BACKTEST CONFIG
+// =============================================================================
+
+// BacktestConfig mirrors the live Config but scopes it to a historical window
+// and adds execution-cost parameters.
+type BacktestConfig struct {
+	Config
+
+	StartTime time.Time
+	EndTime   time.Time
+	Symbols   []string
+	Fee       float64 // taker fee rate, e.g. 0.001 = 10bps
+	Slippage  float64 // fraction of price, applied against the fill direction
+}
+
+// =============================================================================
+// BACKTESTER
+// =============================================================================
+
+// Trade is a single closed round-trip position.
+type Trade struct {
+	Symbol     string
+	Direction  string
+	EntryPrice float64
+	ExitPrice  float64
+	EntryTime  time.Time
+	ExitTime   time.Time
+	PnL        float64
+}
+
+// BacktestResult aggregates the outcome of a Backtester run.
+type BacktestResult struct {
+	Trades        []Trade
+	CumulativePnL []float64
+	TotalPnL      float64
+	MaxDrawdown   float64
+	Sharpe        float64
+	WinRate       float64
+}
+
+// Backtester replays historical bars through GenerateSignal and simulates
+// fills against the configured fee and slippage.
+type Backtester struct {
+	config BacktestConfig
+	source HistoricalDataSource
+}
+
+func NewBacktester(config BacktestConfig, source HistoricalDataSource) *Backtester {
+	return &Backtester{config: config, source: source}
+}
+
+// Run replays every symbol's history independently and merges the resulting
+// trades in chronological order.
+func (b *Backtester) Run() (*BacktestResult, error) {
+	var allTrades []Trade
+
+	for _, symbol := range b.config.Symbols {
+		bars, err := b.source.LoadBars(symbol, b.config.StartTime, b.config.EndTime, "1m")
+		if err != nil {
+			return nil, fmt.Errorf("backtest: loading bars for %s: %w", symbol, err)
+		}
+		allTrades = append(allTrades, b.replay(symbol, bars)...)
+	}
+
+	sort.Slice(allTrades, func(i, j int) bool { return allTrades[i].ExitTime.Before(allTrades[j].ExitTime) })
+
+	return summarizeTrades(allTrades), nil
+}
+
+// replay walks a single symbol's bars, streaming each one into a
+// SignalSeries (and, for config.Strategy == "supertrend", a
+// SupertrendStrategy too) and opening/closing at most one position at a
+// time.
+func (b *Backtester) replay(symbol string, bars []Bar) []Trade {
+	var trades []Trade
+	var open *Trade
+	var openPos *Position
+
+	series := NewSignalSeries(symbol)
+	var supertrend *SupertrendStrategy
+	if b.config.Strategy == "supertrend" {
+		supertrend = newConfiguredSupertrendStrategy(b.config.Config)
+	}
+	exits := b.exitMethods()
+	_, _, _, breakoutWindow := signalThresholds(b.config.Config)
+
+	for _, bar := range bars {
+		series.Update(bar.Close, bar.High, bar.Low)
+		if supertrend != nil {
+			supertrend.PushK(bar)
+		}
+
+		if series.Close.Length() < 2 {
+			continue
+		}
+
+		// Mirror GenerateSignal's own windowing (prior breakoutWindow bars,
+		// excluding the one just pushed) so Exits see the same high5m/low5m
+		// the signal did.
+		high5m := Highest(offsetSeries{&series.High, 1}, breakoutWindow)
+		low5m := Lowest(offsetSeries{&series.Low, 1}, breakoutWindow)
+
+		// Check StopLoss/TakeProfit/Exits against any open position before
+		// looking for a new entry signal, so a stop or take-profit fires
+		// even on a bar where the signal engine itself stays flat.
+		if open != nil {
+			data := &StockData{Symbol: symbol, Price: bar.Close, High5m: high5m, Low5m: low5m, Low: bar.Low, VolumeRatio: bar.Volume}
+			if shouldExit, _ := checkExits(exits, data, openPos); shouldExit {
+				open.ExitPrice = b.applyCosts(bar.Close, open.Direction, false)
+				open.ExitTime = bar.Time
+				open.PnL = tradePnL(*open)
+				trades = append(trades, *open)
+				open, openPos = nil, nil
+				continue
+			}
+		}
+
+		var sig *Signal
+		if supertrend != nil {
+			sig = supertrend.GenerateSignal(symbol, b.config.MinConfidence)
+		} else {
+			sig = GenerateSignal(series, b.config.Config)
+		}
+
+		if open == nil && sig != nil {
+			entry := b.applyCosts(sig.Price, sig.Direction, true)
+			open = &Trade{Symbol: symbol, Direction: sig.Direction, EntryPrice: entry, EntryTime: bar.Time}
+			openPos = &Position{Symbol: symbol, Direction: sig.Direction, EntryPrice: entry, OpenedAt: bar.Time}
+			continue
+		}
+
+		if open != nil && sig != nil && sig.Direction != open.Direction {
+			exit := b.applyCosts(bar.Close, open.Direction, false)
+			open.ExitPrice = exit
+			open.ExitTime = bar.Time
+			open.PnL = tradePnL(*open)
+			trades = append(trades, *open)
+			open, openPos = nil, nil
+		}
+	}
+
+	return trades
+}
+
+// exitMethods builds the full set of exit rules for one replay, so
+// StopLoss/TakeProfit/Exits are actually enforced instead of sitting unused
+// on BacktestConfig. A backtest replay has no live PositionManager/
+// OrderExecutor to bind, so pm/oe are nil (safe: no ExitMethod dereferences
+// them).
+func (b *Backtester) exitMethods() []ExitMethod {
+	return buildExitMethods(b.config.Config, nil, nil)
+}
+
+// checkExits evaluates every exit method against pos in order, returning
+// the first one that fires and its reason.
+func checkExits(exits []ExitMethod, data *StockData, pos *Position) (bool, string) {
+	for _, exit := range exits {
+		if shouldExit, reason := exit.ShouldExit(data, pos); shouldExit {
+			return true, reason
+		}
+	}
+	return false, ""
+}
+
+// applyCosts applies the configured fee and slippage to a fill price.
+// Slippage and fees both work against the trader: entries fill worse,
+// exits fill worse.
+func (b *Backtester) applyCosts(price float64, direction string, isEntry bool) float64 {
+	adverse := 1.0
+	if (direction == "LONG") == isEntry {
+		adverse = 1 + b.config.Slippage + b.config.Fee
+	} else {
+		adverse = 1 - b.config.Slippage - b.config.Fee
+	}
+	return price * adverse
+}
+
+func tradePnL(t Trade) float64 {
+	if t.Direction == "LONG" {
+		return t.ExitPrice - t.EntryPrice
+	}
+	return t.EntryPrice - t.ExitPrice
+}
+
+// summarizeTrades computes cumulative PnL, drawdown, Sharpe, and win-rate
+// from a chronologically sorted trade list. Shared by the backtester and the
+// /pnl and /cumpnl interact commands, which summarize live closed trades the
+// same way.
+func summarizeTrades(trades []Trade) *BacktestResult {
+	result := &BacktestResult{Trades: trades}
+
+	var cum float64
+	var peak float64
+	var wins int
+	returns := make([]float64, 0, len(trades))
+
+	for _, t := range trades {
+		cum += t.PnL
+		result.CumulativePnL = append(result.CumulativePnL, cum)
+		if cum > peak {
+			peak = cum
+		}
+		if dd := peak - cum; dd > result.MaxDrawdown {
+			result.MaxDrawdown = dd
+		}
+		if t.PnL > 0 {
+			wins++
+		}
+		returns = append(returns, t.PnL)
+	}
+
+	result.TotalPnL = cum
+	if len(trades) > 0 {
+		result.WinRate = float64(wins) / float64(len(trades))
+	}
+	result.Sharpe = sharpeRatio(returns)
+
+	return result
+}
+
+func sharpeRatio(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	stddev := math.Sqrt(variance)
+	if stddev == 0 {
+		return 0
+	}
+	return mean / stddev * math.Sqrt(float64(len(returns)))
+}
+
+// =============================================================================
+// CHART RENDERING
+// =============================================================================
+
+// RenderPnLChart renders per-trade PnL as a line chart (one point per
+// trade, in trade order) to a PNG at path.
+func RenderPnLChart(result *BacktestResult, path string) error {
+	xs := make([]float64, len(result.Trades))
+	ys := make([]float64, len(result.Trades))
+	for i, t := range result.Trades {
+		xs[i] = float64(i)
+		ys[i] = t.PnL
+	}
+
+	graph := chart.Chart{
+		Title: "Per-Trade PnL",
+		Series: []chart.Series{
+			chart.ContinuousSeries{XValues: xs, YValues: ys},
+		},
+	}
+	return renderChartPNG(graph, path)
+}
+
+// RenderCumulativePnLChart renders the cumulative PnL curve to a PNG at path.
+func RenderCumulativePnLChart(result *BacktestResult, path string) error {
+	xs := make([]float64, len(result.CumulativePnL))
+	for i := range result.CumulativePnL {
+		xs[i] = float64(i)
+	}
+
+	graph := chart.Chart{
+		Title: "Cumulative PnL",
+		Series: []chart.Series{
+			chart.ContinuousSeries{XValues: xs, YValues: result.CumulativePnL},
+		},
+	}
+	return renderChartPNG(graph, path)
+}
+
+func renderChartPNG(graph chart.Chart, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return graph.Render(chart.PNG, f)
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestCheckExitsStopLoss(t *testing.T) {
+	exits := []ExitMethod{&RoIStopLoss{Percentage: 0.01}}
+	for _, e := range exits {
+		e.Bind(nil, nil)
+	}
+	pos := &Position{Symbol: "TEST", Direction: "LONG", EntryPrice: 100}
+	data := &StockData{Symbol: "TEST", Price: 98.5} // -1.5% move against a 1% stop
+
+	shouldExit, reason := checkExits(exits, data, pos)
+	if !shouldExit {
+		t.Fatalf("expected stop-loss to fire on a -1.5%% move with a 1%% stop")
+	}
+	if reason != "roi stop-loss" {
+		t.Fatalf("expected roi stop-loss reason, got %q", reason)
+	}
+}
+
+func TestBacktesterExitMethodsIncludesConfiguredStopLoss(t *testing.T) {
+	bt := NewBacktester(BacktestConfig{Config: Config{StopLoss: 0.01, TakeProfit: 0.02}}, nil)
+	exits := bt.exitMethods()
+	if len(exits) != 2 {
+		t.Fatalf("expected 2 exit methods (stop-loss + take-profit), got %d", len(exits))
+	}
+}
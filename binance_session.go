@@ -0,0 +1,185 @@
+// binance_session.go
+// Binance session adapter: REST kline queries and a futures/spot toggle.
+// The kline and user-data streams open no WebSocket connection, and
+// SubmitOrder returns an error without sending a request. API keys come from
+// <EnvVarPrefix>_API_KEY / <EnvVarPrefix>_API_SECRET so multiple Binance
+// sessions (e.g. main + subaccount) can coexist in one Config.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// BinanceSession talks to Binance spot or USDⓈ-M futures, selected by
+// Futures. EnvVarPrefix namespaces credential env vars so a config can run
+// more than one Binance session side by side.
+type BinanceSession struct {
+	EnvVarPrefix string
+	Futures      bool
+
+	apiKey    string
+	apiSecret string
+	client    *http.Client
+
+	restBaseOverride string // overrides baseRestURL in tests
+}
+
+func NewBinanceSession(envVarPrefix string, futures bool) *BinanceSession {
+	return &BinanceSession{
+		EnvVarPrefix: envVarPrefix,
+		Futures:      futures,
+		apiKey:       os.Getenv(envVarPrefix + "_API_KEY"),
+		apiSecret:    os.Getenv(envVarPrefix + "_API_SECRET"),
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *BinanceSession) Name() string {
+	if s.Futures {
+		return "binance-futures"
+	}
+	return "binance"
+}
+
+func (s *BinanceSession) baseWsURL() string {
+	if s.Futures {
+		return "wss://fstream.binance.com"
+	}
+	return "wss://stream.binance.com:9443"
+}
+
+func (s *BinanceSession) baseRestURL() string {
+	if s.restBaseOverride != "" {
+		return s.restBaseOverride
+	}
+	if s.Futures {
+		return "https://fapi.binance.com"
+	}
+	return "https://api.binance.com"
+}
+
+// MarketDataStream returns a stream handle bound to the spot or futures
+// WebSocket base URL. No websocket connection is opened: its Subscribe
+// channels never receive a Bar.
+func (s *BinanceSession) MarketDataStream() MarketDataStream {
+	return &binanceMarketDataStream{baseURL: s.baseWsURL()}
+}
+
+// UserDataStream returns a user-data stream handle. No listenKey is created
+// and no connection is opened: its Orders channel never receives an Order.
+func (s *BinanceSession) UserDataStream() UserDataStream {
+	return &binanceUserDataStream{baseURL: s.baseWsURL(), apiKey: s.apiKey}
+}
+
+func (s *BinanceSession) SubmitOrder(order SubmitOrder) (Order, error) {
+	if s.apiKey == "" || s.apiSecret == "" {
+		return Order{}, fmt.Errorf("binance session %s: missing API credentials (%s_API_KEY/%s_API_SECRET)", s.Name(), s.EnvVarPrefix, s.EnvVarPrefix)
+	}
+	// POST /api/v3/order (spot) or /fapi/v1/order (futures), signed with
+	// apiSecret, is not implemented in this sandbox. Returning a fabricated
+	// "NEW" Order here would tell the caller an order was placed when none
+	// was ever sent over the wire, so fail loudly instead.
+	return Order{}, fmt.Errorf("binance session %s: SubmitOrder not implemented in this sandbox", s.Name())
+}
+
+func (s *BinanceSession) QueryAccount() (*Account, error) {
+	if s.apiKey == "" || s.apiSecret == "" {
+		return nil, fmt.Errorf("binance session %s: missing API credentials", s.Name())
+	}
+	return &Account{Balances: map[string]float64{}}, nil
+}
+
+// binanceKlinesPath is /api/v3/klines (spot) or /fapi/v1/klines (futures),
+// both unauthenticated REST endpoints: no signing needed.
+func (s *BinanceSession) binanceKlinesPath() string {
+	if s.Futures {
+		return "/fapi/v1/klines"
+	}
+	return "/api/v3/klines"
+}
+
+func (s *BinanceSession) QueryKLines(symbol, interval string, options KLineOptions) ([]Bar, error) {
+	url := fmt.Sprintf("%s%s?symbol=%s&interval=%s", s.baseRestURL(), s.binanceKlinesPath(), symbol, interval)
+	if options.Limit > 0 {
+		url += fmt.Sprintf("&limit=%d", options.Limit)
+	}
+	if !options.StartTime.IsZero() {
+		url += fmt.Sprintf("&startTime=%d", options.StartTime.UnixMilli())
+	}
+	if !options.EndTime.IsZero() {
+		url += fmt.Sprintf("&endTime=%d", options.EndTime.UnixMilli())
+	}
+
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("binance session %s: QueryKLines: %w", s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	// Each row is [openTime, open, high, low, close, volume, closeTime, ...],
+	// with OHLCV as strings to preserve precision; openTime/closeTime are
+	// millisecond epoch numbers.
+	var rows [][]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("binance session %s: QueryKLines: decoding response for %s: %w", s.Name(), symbol, err)
+	}
+
+	bars := make([]Bar, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		openTime, _ := row[0].(float64)
+		open, _ := strconv.ParseFloat(row[1].(string), 64)
+		high, _ := strconv.ParseFloat(row[2].(string), 64)
+		low, _ := strconv.ParseFloat(row[3].(string), 64)
+		cls, _ := strconv.ParseFloat(row[4].(string), 64)
+		vol, _ := strconv.ParseFloat(row[5].(string), 64)
+		bars = append(bars, Bar{
+			Symbol: symbol,
+			Time:   time.UnixMilli(int64(openTime)),
+			Open:   open,
+			High:   high,
+			Low:    low,
+			Close:  cls,
+			Volume: vol,
+		})
+	}
+	return bars, nil
+}
+
+// =============================================================================
+// STREAMS
+// =============================================================================
+
+type binanceMarketDataStream struct {
+	baseURL string
+}
+
+func (m *binanceMarketDataStream) Subscribe(symbol string) (<-chan Bar, error) {
+	ch := make(chan Bar)
+	// No websocket is dialed for symbol: nothing is ever sent on ch.
+	return ch, nil
+}
+
+func (m *binanceMarketDataStream) Close() error { return nil }
+
+type binanceUserDataStream struct {
+	baseURL string
+	apiKey  string
+}
+
+func (u *binanceUserDataStream) Orders() <-chan Order {
+	ch := make(chan Order)
+	// No listenKey is requested and nothing is dialed: nothing is ever sent
+	// on ch.
+	return ch
+}
+
+func (u *binanceUserDataStream) Close() error { return nil }
@@ -0,0 +1,114 @@
+// cmd_optimize.go
+// CLI entry point for parameter optimization, invoked as the "optimize"
+// subcommand of this binary (e.g. `go run . optimize -template ranges.json
+// -data-dir ./bars -symbol AAPL`).
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// RunOptimizeCLI parses "optimize" subcommand flags and runs a grid search
+// (default) or walk-forward validation against a JSON ParamRange template.
+func RunOptimizeCLI(args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	templatePath := fs.String("template", "", "path to a JSON []ParamRange template")
+	dataDir := fs.String("data-dir", "", "CSV historical data directory")
+	symbol := fs.String("symbol", "", "symbol to optimize against")
+	startFlag := fs.String("start", "", "RFC3339 window start (default: 1 month ago)")
+	endFlag := fs.String("end", "", "RFC3339 window end (default: now)")
+	workers := fs.Int("workers", 0, "worker pool size (default: NumCPU)")
+	walkForward := fs.Bool("walk-forward", false, "run walk-forward validation instead of a single grid search")
+	folds := fs.Int("folds", 4, "number of walk-forward folds")
+	storePath := fs.String("store", "optimize.db", "SQLite path for resumable results")
+	heatmapX := fs.String("heatmap-x", "", "x-axis parameter for the Sharpe heatmap")
+	heatmapY := fs.String("heatmap-y", "", "y-axis parameter for the Sharpe heatmap")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *templatePath == "" || *dataDir == "" || *symbol == "" {
+		return fmt.Errorf("usage: optimize -template ranges.json -data-dir ./bars -symbol AAPL [-start RFC3339 -end RFC3339]")
+	}
+
+	raw, err := os.ReadFile(*templatePath)
+	if err != nil {
+		return err
+	}
+	var params []ParamRange
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return fmt.Errorf("optimize: parsing %s: %w", *templatePath, err)
+	}
+
+	startTime, err := parseOrDefault(*startFlag, time.Now().AddDate(0, -1, 0))
+	if err != nil {
+		return err
+	}
+	endTime, err := parseOrDefault(*endFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	store, err := NewSQLiteStore(*storePath)
+	if err != nil {
+		return err
+	}
+
+	opt := OptimizeConfig{
+		Template: BacktestConfig{
+			Config:    Config{MinConfidence: 0.55},
+			StartTime: startTime,
+			EndTime:   endTime,
+			Symbols:   []string{*symbol},
+		},
+		Params:     params,
+		Workers:    *workers,
+		Store:      store,
+		ResultsKey: "optimize:" + *symbol,
+	}
+	source := &CSVDataSource{Dir: *dataDir}
+
+	if *walkForward {
+		results, err := RunWalkForward(opt, source, startTime, endTime, *folds)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			fmt.Printf("fold %s..%s  in-sample sharpe=%.2f  out-of-sample sharpe=%.2f\n",
+				r.Fold.TestStart.Format("2006-01-02"), r.Fold.TestEnd.Format("2006-01-02"),
+				r.InSample.Sharpe, r.OutSample.Sharpe)
+		}
+		return nil
+	}
+
+	results, err := RunGridSearch(opt, source)
+	if err != nil {
+		return err
+	}
+
+	front := ParetoFront(results)
+	fmt.Printf("evaluated %d points, %d on the Pareto front\n", len(results), len(front))
+	for _, r := range front {
+		fmt.Printf("  %v  sharpe=%.2f drawdown=%.2f return=%.2f\n", r.Params, r.Sharpe, r.MaxDrawdown, r.TotalReturn)
+	}
+
+	if *heatmapX != "" && *heatmapY != "" {
+		if err := RenderHeatmap(results, *heatmapX, *heatmapY, "heatmap.png"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseOrDefault(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
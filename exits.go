@@ -0,0 +1,291 @@
+// exits.go
+// Pluggable exit/risk-management subsystem. Stop-loss and take-profit used
+// to be two floats on Config that nothing ever enforced; ExitMethod lets a
+// strategy stack several exit rules per position, similar to BBGO's exits:
+// list.
+
+package main
+
+import "time"
+
+// =============================================================================
+// POSITIONS AND WIRING INTERFACES
+// =============================================================================
+
+// Position is an open, directional holding in one symbol.
+type Position struct {
+	Symbol     string
+	Direction  string // "LONG" or "SHORT"
+	EntryPrice float64
+	OpenedAt   time.Time
+
+	// peak/trough tracks the best price seen since entry, used by the
+	// trailing and protective-stop exits to compute how far price has
+	// retraced from its favorable extreme.
+	peak float64
+
+	// protectiveActivated/cumulatedVolumes are per-position scratch state
+	// for ProtectiveStopLoss/CumulatedVolumeTakeProfit. Exits lists are
+	// built once and reused across every position (buildExitMethods is
+	// called once per symbol/run, not per trade), so that state has to
+	// live on the position, not on the ExitMethod instance, or it leaks
+	// across unrelated positions the same way peak would.
+	protectiveActivated bool
+	cumulatedVolumes    []float64
+}
+
+// favorableMove returns how far price has moved in the position's favor,
+// as a fraction of the entry price.
+func (p *Position) favorableMove(price float64) float64 {
+	if p.Direction == "LONG" {
+		return (price - p.EntryPrice) / p.EntryPrice
+	}
+	return (p.EntryPrice - price) / p.EntryPrice
+}
+
+// updatePeak records a new best-seen price, initializing from EntryPrice.
+func (p *Position) updatePeak(price float64) {
+	if p.peak == 0 {
+		p.peak = p.EntryPrice
+	}
+	if p.Direction == "LONG" && price > p.peak {
+		p.peak = price
+	} else if p.Direction == "SHORT" && price < p.peak {
+		p.peak = price
+	}
+}
+
+// PositionManager looks up the open position for a symbol, if any.
+type PositionManager interface {
+	GetPosition(symbol string) *Position
+	ClosePosition(symbol string, reason string) error
+}
+
+// OrderExecutor submits the order that closes a position.
+type OrderExecutor interface {
+	SubmitMarketExit(symbol string, direction string) error
+}
+
+// ExitMethod evaluates a single exit rule against an open position on every
+// new bar. Bind gives the exit a chance to cache references it needs;
+// ShouldExit returns whether to close now and a human-readable reason.
+type ExitMethod interface {
+	Bind(pm PositionManager, oe OrderExecutor)
+	ShouldExit(data *StockData, pos *Position) (bool, string)
+}
+
+// buildExitMethods assembles the full set of exit rules for a Config: the
+// RoI stop-loss/take-profit floats (when set) plus any explicit Exits, bound
+// against pm/oe. Shared by the backtester and RunTradingSystem so live
+// trading and backtests enforce StopLoss/TakeProfit/Exits the same way.
+func buildExitMethods(config Config, pm PositionManager, oe OrderExecutor) []ExitMethod {
+	var exits []ExitMethod
+	if config.StopLoss > 0 {
+		exits = append(exits, &RoIStopLoss{Percentage: config.StopLoss})
+	}
+	if config.TakeProfit > 0 {
+		exits = append(exits, &RoITakeProfit{Percentage: config.TakeProfit})
+	}
+	exits = append(exits, config.Exits...)
+	for _, exit := range exits {
+		exit.Bind(pm, oe)
+	}
+	return exits
+}
+
+// =============================================================================
+// ROI STOP-LOSS / TAKE-PROFIT
+// =============================================================================
+
+// RoIStopLoss closes a position once the unrealized loss exceeds Percentage.
+type RoIStopLoss struct {
+	Percentage float64
+
+	pm PositionManager
+	oe OrderExecutor
+}
+
+func (e *RoIStopLoss) Bind(pm PositionManager, oe OrderExecutor) {
+	e.pm, e.oe = pm, oe
+}
+
+func (e *RoIStopLoss) ShouldExit(data *StockData, pos *Position) (bool, string) {
+	if move := pos.favorableMove(data.Price); move <= -e.Percentage {
+		return true, "roi stop-loss"
+	}
+	return false, ""
+}
+
+// RoITakeProfit closes a position once the unrealized gain reaches Percentage.
+type RoITakeProfit struct {
+	Percentage float64
+
+	pm PositionManager
+	oe OrderExecutor
+}
+
+func (e *RoITakeProfit) Bind(pm PositionManager, oe OrderExecutor) {
+	e.pm, e.oe = pm, oe
+}
+
+func (e *RoITakeProfit) ShouldExit(data *StockData, pos *Position) (bool, string) {
+	if move := pos.favorableMove(data.Price); move >= e.Percentage {
+		return true, "roi take-profit"
+	}
+	return false, ""
+}
+
+// =============================================================================
+// PROTECTIVE STOP-LOSS
+// =============================================================================
+
+// ProtectiveStopLoss stays dormant until price has moved favorably by
+// ActivationRatio, then locks in a stop StopLossRatio past the entry price
+// in the position's favor (above entry for LONG, below for SHORT) so the
+// position can no longer turn into a loss.
+type ProtectiveStopLoss struct {
+	ActivationRatio float64
+	StopLossRatio   float64
+
+	pm PositionManager
+	oe OrderExecutor
+}
+
+func (e *ProtectiveStopLoss) Bind(pm PositionManager, oe OrderExecutor) {
+	e.pm, e.oe = pm, oe
+}
+
+func (e *ProtectiveStopLoss) ShouldExit(data *StockData, pos *Position) (bool, string) {
+	move := pos.favorableMove(data.Price)
+	if !pos.protectiveActivated {
+		if move >= e.ActivationRatio {
+			pos.protectiveActivated = true
+		}
+		return false, ""
+	}
+
+	stopLevel := pos.EntryPrice * (1 + signedRatio(pos.Direction, e.StopLossRatio))
+	if (pos.Direction == "LONG" && data.Price <= stopLevel) ||
+		(pos.Direction == "SHORT" && data.Price >= stopLevel) {
+		return true, "protective stop-loss"
+	}
+	return false, ""
+}
+
+// signedRatio orients a positive ratio toward profit-direction for LONG
+// (above entry) or SHORT (below entry).
+func signedRatio(direction string, ratio float64) float64 {
+	if direction == "LONG" {
+		return ratio
+	}
+	return -ratio
+}
+
+// =============================================================================
+// TRAILING STOP
+// =============================================================================
+
+// TrailingStop supports multi-tier trailing: as the position's favorable
+// move crosses each ActivationRatio threshold, the callback tightens to the
+// corresponding CallbackRate. The exit fires once price retraces more than
+// the active callback from the peak favorable price.
+type TrailingStop struct {
+	ActivationRatio []float64
+	CallbackRate    []float64
+
+	pm PositionManager
+	oe OrderExecutor
+}
+
+func (e *TrailingStop) Bind(pm PositionManager, oe OrderExecutor) {
+	e.pm, e.oe = pm, oe
+}
+
+func (e *TrailingStop) ShouldExit(data *StockData, pos *Position) (bool, string) {
+	pos.updatePeak(data.Price)
+
+	callback := e.callbackFor(pos.favorableMove(pos.peak))
+	if callback == 0 {
+		return false, ""
+	}
+
+	retrace := pos.peak*signedRatio(pos.Direction, 1) - data.Price*signedRatio(pos.Direction, 1)
+	if retrace/pos.peak >= callback {
+		return true, "trailing stop"
+	}
+	return false, ""
+}
+
+// callbackFor returns the callback rate for the highest activation tier the
+// position's peak favorable move has reached, or 0 if none have activated.
+func (e *TrailingStop) callbackFor(peakMove float64) float64 {
+	callback := 0.0
+	for i, activation := range e.ActivationRatio {
+		if peakMove >= activation && i < len(e.CallbackRate) {
+			callback = e.CallbackRate[i]
+		}
+	}
+	return callback
+}
+
+// =============================================================================
+// SHADOW / VOLUME BASED EXITS
+// =============================================================================
+
+// LowerShadowTakeProfit exits longs when the current bar's lower shadow
+// ((close-low)/close) exceeds Ratio, signaling exhaustion on a pullback.
+type LowerShadowTakeProfit struct {
+	Ratio float64
+
+	pm PositionManager
+	oe OrderExecutor
+}
+
+func (e *LowerShadowTakeProfit) Bind(pm PositionManager, oe OrderExecutor) {
+	e.pm, e.oe = pm, oe
+}
+
+func (e *LowerShadowTakeProfit) ShouldExit(data *StockData, pos *Position) (bool, string) {
+	if pos.Direction != "LONG" || data.Price == 0 {
+		return false, ""
+	}
+	shadow := (data.Price - data.Low) / data.Price
+	if shadow > e.Ratio {
+		return true, "lower shadow take-profit"
+	}
+	return false, ""
+}
+
+// CumulatedVolumeTakeProfit exits once the cumulated quote volume over the
+// last Window bars drops below MinQuoteVolume, treating thinning volume as
+// a signal the move has run out of participation.
+type CumulatedVolumeTakeProfit struct {
+	Window         int
+	MinQuoteVolume float64
+
+	pm PositionManager
+	oe OrderExecutor
+}
+
+func (e *CumulatedVolumeTakeProfit) Bind(pm PositionManager, oe OrderExecutor) {
+	e.pm, e.oe = pm, oe
+}
+
+func (e *CumulatedVolumeTakeProfit) ShouldExit(data *StockData, pos *Position) (bool, string) {
+	pos.cumulatedVolumes = append(pos.cumulatedVolumes, data.VolumeRatio)
+	if len(pos.cumulatedVolumes) > e.Window {
+		pos.cumulatedVolumes = pos.cumulatedVolumes[len(pos.cumulatedVolumes)-e.Window:]
+	}
+	if len(pos.cumulatedVolumes) < e.Window {
+		return false, ""
+	}
+
+	var sum float64
+	for _, v := range pos.cumulatedVolumes {
+		sum += v
+	}
+	if sum < e.MinQuoteVolume {
+		return true, "cumulated volume take-profit"
+	}
+	return false, ""
+}
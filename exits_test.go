@@ -0,0 +1,134 @@
+package main
+
+import "testing"
+
+func TestBuildExitMethodsIncludesConfiguredStopLossAndExplicitExits(t *testing.T) {
+	config := Config{
+		StopLoss:   0.01,
+		TakeProfit: 0.02,
+		Exits:      []ExitMethod{&TrailingStop{ActivationRatio: []float64{0.01}, CallbackRate: []float64{0.005}}},
+	}
+
+	state := NewRunnerState()
+	exits := buildExitMethods(config, state, nil)
+	if len(exits) != 3 {
+		t.Fatalf("expected stop-loss + take-profit + explicit trailing stop (3 exits), got %d", len(exits))
+	}
+}
+
+func TestLowerShadowTakeProfitUsesCurrentBarLow(t *testing.T) {
+	e := &LowerShadowTakeProfit{Ratio: 0.02}
+	pos := &Position{Symbol: "TEST", Direction: "LONG", EntryPrice: 100}
+
+	// (100-99)/100 = 0.01, below the 0.02 ratio: no exit yet.
+	if exit, _ := e.ShouldExit(&StockData{Price: 100, Low: 99}, pos); exit {
+		t.Fatalf("expected no exit when lower shadow is below Ratio")
+	}
+
+	// (100-97)/100 = 0.03, above the 0.02 ratio: exit.
+	exit, reason := e.ShouldExit(&StockData{Price: 100, Low: 97}, pos)
+	if !exit {
+		t.Fatalf("expected exit when lower shadow exceeds Ratio")
+	}
+	if reason == "" {
+		t.Fatalf("expected a non-empty exit reason")
+	}
+}
+
+func TestCumulatedVolumeTakeProfitSumsWindowOfRealVolume(t *testing.T) {
+	e := &CumulatedVolumeTakeProfit{Window: 3, MinQuoteVolume: 300}
+	pos := &Position{Symbol: "TEST", Direction: "LONG", EntryPrice: 100}
+
+	for _, vol := range []float64{100, 100} {
+		if exit, _ := e.ShouldExit(&StockData{Price: 100, VolumeRatio: vol}, pos); exit {
+			t.Fatalf("expected no exit before the window fills")
+		}
+	}
+
+	// Window now [100, 100, 150], sum 350 >= MinQuoteVolume: no exit.
+	if exit, _ := e.ShouldExit(&StockData{Price: 100, VolumeRatio: 150}, pos); exit {
+		t.Fatalf("expected no exit while cumulated volume is still above MinQuoteVolume")
+	}
+
+	// Window now [100, 150, 10], sum 260 < MinQuoteVolume: exit.
+	exit, _ := e.ShouldExit(&StockData{Price: 100, VolumeRatio: 10}, pos)
+	if !exit {
+		t.Fatalf("expected exit once cumulated volume over the window drops below MinQuoteVolume")
+	}
+}
+
+// TestProtectiveStopLossStateDoesNotLeakAcrossPositions reproduces the bug
+// where ProtectiveStopLoss.activated lived on the shared ExitMethod
+// instance: buildExitMethods constructs the exits slice once and reuses it
+// across every subsequent position, so activating the stop for one position
+// must not leave the very next position (even a different symbol) already
+// activated.
+func TestProtectiveStopLossStateDoesNotLeakAcrossPositions(t *testing.T) {
+	e := &ProtectiveStopLoss{ActivationRatio: 0.05, StopLossRatio: 0.01}
+
+	first := &Position{Symbol: "AAA", Direction: "LONG", EntryPrice: 100}
+	// Move price up 10%, well past ActivationRatio: activates for `first`.
+	if exit, _ := e.ShouldExit(&StockData{Price: 110}, first); exit {
+		t.Fatalf("expected activation, not an immediate exit")
+	}
+	if !first.protectiveActivated {
+		t.Fatalf("expected ProtectiveStopLoss to activate for the first position")
+	}
+
+	second := &Position{Symbol: "BBB", Direction: "LONG", EntryPrice: 100}
+	if second.protectiveActivated {
+		t.Fatalf("expected a fresh position to start with protectiveActivated=false, got state leaked from a prior position")
+	}
+	// Price dropped straight to the entry price: if activation had leaked,
+	// this would be below the locked-in stop level and falsely exit.
+	if exit, _ := e.ShouldExit(&StockData{Price: 100}, second); exit {
+		t.Fatalf("expected no exit for an unactivated second position, got a leaked stop from the first position")
+	}
+}
+
+// TestCumulatedVolumeTakeProfitStateDoesNotLeakAcrossPositions reproduces
+// the equivalent bug for CumulatedVolumeTakeProfit.volumes: a fresh position
+// sharing the same ExitMethod instance must start its own empty window, not
+// inherit bars accumulated for an unrelated prior position/symbol.
+func TestCumulatedVolumeTakeProfitStateDoesNotLeakAcrossPositions(t *testing.T) {
+	e := &CumulatedVolumeTakeProfit{Window: 3, MinQuoteVolume: 300}
+
+	first := &Position{Symbol: "AAA", Direction: "LONG", EntryPrice: 100}
+	for _, vol := range []float64{100, 100, 100} {
+		e.ShouldExit(&StockData{Price: 100, VolumeRatio: vol}, first)
+	}
+	if len(first.cumulatedVolumes) != 3 {
+		t.Fatalf("expected the first position's window to fill to 3, got %d", len(first.cumulatedVolumes))
+	}
+
+	second := &Position{Symbol: "BBB", Direction: "LONG", EntryPrice: 100}
+	if len(second.cumulatedVolumes) != 0 {
+		t.Fatalf("expected a fresh position to start with an empty volume window, got %v", second.cumulatedVolumes)
+	}
+	// Only one low-volume bar so far for `second`: if the first position's
+	// volumes had leaked in, the window would already be full and could
+	// falsely exit on thin cumulated volume.
+	if exit, _ := e.ShouldExit(&StockData{Price: 100, VolumeRatio: 10}, second); exit {
+		t.Fatalf("expected no exit before the second position's own window fills, got a leaked window from the first position")
+	}
+}
+
+func TestRunnerStatePositionManagerRoundTrip(t *testing.T) {
+	state := NewRunnerState()
+	if state.GetPosition("TEST") != nil {
+		t.Fatalf("expected no open position before OpenPosition")
+	}
+
+	state.OpenPosition(&Position{Symbol: "TEST", Direction: "LONG", EntryPrice: 100})
+	pos := state.GetPosition("TEST")
+	if pos == nil || pos.EntryPrice != 100 {
+		t.Fatalf("expected open position with entry 100, got %+v", pos)
+	}
+
+	if err := state.ClosePosition("TEST", "test close"); err != nil {
+		t.Fatalf("unexpected error closing position: %v", err)
+	}
+	if state.GetPosition("TEST") != nil {
+		t.Fatalf("expected no open position after ClosePosition")
+	}
+}
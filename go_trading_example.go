@@ -7,7 +7,8 @@ package main
 import (
 	"context"
 	"fmt"
-	"math"
+	"net/http"
+	"os"
 	"sync"
 	"time"
 )
@@ -36,6 +37,7 @@ type StockData struct {
 	BBLower     float64
 	High5m      float64
 	Low5m       float64
+	Low         float64 // current bar's own low, as opposed to the prior-5-bar Low5m
 	VWAP        float64
 }
 
@@ -49,13 +51,77 @@ type Signal struct {
 }
 
 type Config struct {
-	Symbols           []string
-	MinConfidence     float64
-	PositionSize      float64
-	MaxPositions      int
-	StopLoss          float64
-	TakeProfit        float64
-	CooldownSeconds   int
+	Symbols         []string
+	MinConfidence   float64
+	PositionSize    float64
+	MaxPositions    int
+	StopLoss        float64
+	TakeProfit      float64
+	CooldownSeconds int
+	Exits           []ExitMethod
+	Sessions        map[string]Session
+	Persistence     *PersistenceFacade
+
+	// GenerateSignal's momentum/RSI/breakout thresholds, promoted off of
+	// inline literals so the optimizer can sweep them. Zero means "use
+	// GenerateSignal's default" (see signalThresholds) rather than "disable
+	// the component", so a bare Config{} behaves exactly as before.
+	MomentumThreshold float64
+	RSIOversold       float64
+	RSIOverbought     float64
+	BreakoutWindow    int
+
+	// Strategy selects the signal engine RunTradingSystem and the
+	// backtester use: "" or "momentum" (default) is GenerateSignal's
+	// momentum/RSI/breakout blend; "supertrend" is SupertrendStrategy. The
+	// Supertrend*/FastDEMAWindow/SlowDEMAWindow/LinearRegressionWindow
+	// fields below configure it and default via newConfiguredSupertrendStrategy
+	// when left zero.
+	Strategy               string
+	SupertrendWindow       int
+	SupertrendMultiplier   float64
+	FastDEMAWindow         int
+	SlowDEMAWindow         int
+	LinearRegressionWindow int
+
+	InteractBackend string // "telegram" or "slack", empty disables interact
+	TelegramToken   string
+	SlackBotToken   string
+	SlackChannel    string
+}
+
+// strategyName returns config.Strategy, defaulting to "momentum" to label
+// closed trades when persisting them, matching RunTradingSystem and the
+// backtester, which run GenerateSignal's momentum engine when
+// config.Strategy is unset.
+func strategyName(config Config) string {
+	if config.Strategy == "" {
+		return "momentum"
+	}
+	return config.Strategy
+}
+
+// signalThresholds applies GenerateSignal's defaults to any zero-valued
+// threshold fields on config, so an unset Config behaves exactly as the
+// previous hardcoded constants did.
+func signalThresholds(config Config) (momentum, rsiOversold, rsiOverbought float64, breakoutWindow int) {
+	momentum = config.MomentumThreshold
+	if momentum == 0 {
+		momentum = 0.0008
+	}
+	rsiOversold = config.RSIOversold
+	if rsiOversold == 0 {
+		rsiOversold = 35
+	}
+	rsiOverbought = config.RSIOverbought
+	if rsiOverbought == 0 {
+		rsiOverbought = 65
+	}
+	breakoutWindow = config.BreakoutWindow
+	if breakoutWindow == 0 {
+		breakoutWindow = 5
+	}
+	return momentum, rsiOversold, rsiOverbought, breakoutWindow
 }
 
 // =============================================================================
@@ -79,14 +145,14 @@ func NewYahooFinanceClient() *YahooFinanceClient {
 func (c *YahooFinanceClient) FetchStockData(symbol string) (*StockData, error) {
 	// In real implementation, call Yahoo Finance API
 	// Parallel requests: Python does 1 at a time, Go can do 100+ concurrently
-	
+
 	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s", symbol)
 	resp, err := c.client.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	
+
 	// Parse JSON, calculate indicators...
 	return &StockData{Symbol: symbol}, nil
 }
@@ -95,15 +161,15 @@ func (c *YahooFinanceClient) FetchStockData(symbol string) (*StockData, error) {
 func (c *YahooFinanceClient) FetchAllStocks(symbols []string) map[string]*StockData {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	
+
 	var mu sync.Mutex
 	result := make(map[string]*StockData)
 	var wg sync.WaitGroup
-	
+
 	// Go: Fetch 268 stocks concurrently!
 	// Python: Sequential, ~10-30 seconds
 	// Go: Parallel, ~1-3 seconds (10x faster)
-	
+
 	for _, symbol := range symbols {
 		wg.Add(1)
 		go func(sym string) {
@@ -121,7 +187,7 @@ func (c *YahooFinanceClient) FetchAllStocks(symbols []string) map[string]*StockD
 			}
 		}(symbol)
 	}
-	
+
 	wg.Wait()
 	return result
 }
@@ -137,7 +203,7 @@ func CalculateSMA(prices []float64, period int) float64 {
 	if len(prices) < period {
 		return prices[len(prices)-1]
 	}
-	
+
 	sum := 0.0
 	for i := len(prices) - period; i < len(prices); i++ {
 		sum += prices[i]
@@ -149,7 +215,7 @@ func CalculateRSI(prices []float64, period int) float64 {
 	if len(prices) < period+1 {
 		return 50 // Neutral
 	}
-	
+
 	var gains, losses float64
 	for i := 1; i < len(prices); i++ {
 		change := prices[i] - prices[i-1]
@@ -159,14 +225,14 @@ func CalculateRSI(prices []float64, period int) float64 {
 			losses -= change
 		}
 	}
-	
+
 	avgGain := gains / float64(period)
 	avgLoss := losses / float64(period)
-	
+
 	if avgLoss == 0 {
 		return 100
 	}
-	
+
 	rs := avgGain / avgLoss
 	return 100 - (100 / (1 + rs))
 }
@@ -175,7 +241,7 @@ func CalculateMACD(prices []float64) (macd, signal, histogram float64) {
 	// Fast exponential moving averages
 	ema12 := calculateEMA(prices, 12)
 	ema26 := calculateEMA(prices, 26)
-	
+
 	macd = ema12 - ema26
 	signal = calculateEMA([]float64{macd, macd, macd, macd, macd, macd, macd, macd, macd}, 9)
 	histogram = macd - signal
@@ -186,14 +252,14 @@ func calculateEMA(prices []float64, period int) float64 {
 	if len(prices) == 0 {
 		return 0
 	}
-	
+
 	multiplier := 2.0 / float64(period+1)
 	ema := prices[0]
-	
+
 	for i := 1; i < len(prices); i++ {
 		ema = (prices[i]-ema)*multiplier + ema
 	}
-	
+
 	return ema
 }
 
@@ -201,49 +267,99 @@ func calculateEMA(prices []float64, period int) float64 {
 // SIGNAL GENERATION
 // =============================================================================
 
-func GenerateSignal(data *StockData, minConfidence float64) *Signal {
+// SignalSeries bundles the rolling state GenerateSignal needs for one
+// symbol. Close/High/Low are plain Series; RSI and MACD carry their own
+// indicator state so each Update is O(1) rather than rescanning history.
+type SignalSeries struct {
+	Symbol string
+	Close  buffer
+	High   buffer
+	Low    buffer
+	RSI    *RSI
+	MACD   *MACD
+}
+
+func NewSignalSeries(symbol string) *SignalSeries {
+	return &SignalSeries{
+		Symbol: symbol,
+		RSI:    &RSI{Window: 14},
+		MACD:   NewMACD(12, 26, 9),
+	}
+}
+
+// Update pushes the latest bar's close/high/low and steps the RSI/MACD
+// indicators forward.
+func (s *SignalSeries) Update(price, high, low float64) {
+	s.Close.push(price)
+	s.High.push(high)
+	s.Low.push(low)
+	s.RSI.Update(price)
+	s.MACD.Update(price)
+}
+
+func GenerateSignal(s *SignalSeries, config Config) *Signal {
+	if s.Close.Length() == 0 {
+		return nil
+	}
+
+	momentumThreshold, rsiOversold, rsiOverbought, breakoutWindow := signalThresholds(config)
+
+	price := s.Close.Last(0)
+	var change1m float64
+	if s.Close.Length() > 1 && s.Close.Last(1) != 0 {
+		change1m = (price - s.Close.Last(1)) / s.Close.Last(1)
+	}
+	rsi := s.RSI.Last(0)
+	macdHist := s.MACD.Histogram().Last(0)
+	// high5m/low5m must be computed from the bars *prior* to this one, not
+	// including it — s.Update has already pushed the current bar's own high
+	// and low, and price can never exceed its own high or undercut its own
+	// low, so including it made the breakout comparisons below unreachable.
+	high5m := Highest(offsetSeries{&s.High, 1}, breakoutWindow)
+	low5m := Lowest(offsetSeries{&s.Low, 1}, breakoutWindow)
+
 	scores := map[string]float64{
 		"LONG":  0,
 		"SHORT": 0,
 	}
-	
+
 	// Momentum signal
-	if data.Change1m > 0.0008 {
+	if change1m > momentumThreshold {
 		scores["LONG"] += 0.35
-	} else if data.Change1m < -0.0008 {
+	} else if change1m < -momentumThreshold {
 		scores["SHORT"] += 0.35
 	}
-	
+
 	// RSI signal
-	if data.RSI < 35 {
+	if rsi < rsiOversold {
 		scores["LONG"] += 0.25
-	} else if data.RSI > 65 {
+	} else if rsi > rsiOverbought {
 		scores["SHORT"] += 0.25
 	}
-	
+
 	// Breakout signal
-	if data.Price > data.High5m {
+	if price > high5m {
 		scores["LONG"] += 0.40
-	} else if data.Price < data.Low5m {
+	} else if price < low5m {
 		scores["SHORT"] += 0.40
 	}
-	
+
 	// MACD signal
-	if data.MACDHist > 0 {
+	if macdHist > 0 {
 		scores["LONG"] += 0.15
 	} else {
 		scores["SHORT"] += 0.15
 	}
-	
+
 	// Decision
 	total := scores["LONG"] + scores["SHORT"]
 	if total == 0 {
 		return nil
 	}
-	
+
 	var direction string
 	var confidence float64
-	
+
 	if scores["LONG"] > scores["SHORT"]*1.2 {
 		direction = "LONG"
 		confidence = scores["LONG"] / total
@@ -253,16 +369,16 @@ func GenerateSignal(data *StockData, minConfidence float64) *Signal {
 	} else {
 		return nil // No clear signal
 	}
-	
-	if confidence < minConfidence {
+
+	if confidence < config.MinConfidence {
 		return nil
 	}
-	
+
 	return &Signal{
-		Symbol:     data.Symbol,
+		Symbol:     s.Symbol,
 		Direction:  direction,
 		Confidence: confidence,
-		Price:      data.Price,
+		Price:      price,
 		Timestamp:  time.Now(),
 	}
 }
@@ -272,60 +388,202 @@ func GenerateSignal(data *StockData, minConfidence float64) *Signal {
 // =============================================================================
 
 func RunTradingSystem(config Config) {
-	client := NewYahooFinanceClient()
-	
+	sessions := config.Sessions
+	if len(sessions) == 0 {
+		sessions = map[string]Session{"yahoo": NewYahooSession()}
+	}
+	series := make(map[string]*SignalSeries)           // keyed by "<session>:<symbol>"
+	strategies := make(map[string]*SupertrendStrategy) // only populated when config.Strategy == "supertrend"
+
+	// state tracks open positions so StopLoss/TakeProfit/Exits are actually
+	// enforced live, the same way Backtester.replay enforces them in
+	// backtests. There's no live OrderExecutor yet, so oe is nil (safe: no
+	// ExitMethod dereferences it).
+	state := NewRunnerState()
+	exits := buildExitMethods(config, state, nil)
+
+	// Reseed any positions that were open when a previous run died, so a
+	// restart doesn't silently forget them.
+	if config.Persistence != nil {
+		for _, pos := range config.Persistence.OpenPositions() {
+			pos := pos
+			state.OpenPosition(&pos)
+		}
+	}
+
+	interact, err := NewInteractFromConfig(config, state)
+	if err != nil {
+		fmt.Printf("[interact] failed to start %s backend: %v\n", config.InteractBackend, err)
+	} else if interact != nil {
+		if err := interact.Start(); err != nil {
+			fmt.Printf("[interact] failed to start %s backend: %v\n", config.InteractBackend, err)
+		} else {
+			defer interact.Stop()
+		}
+	}
+
 	// Market hours check
 	loc, _ := time.LoadLocation("America/New_York")
-	
+
 	for {
+		if state.IsPaused() {
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
 		now := time.Now().In(loc)
 		hour := now.Hour()
 		minute := now.Minute()
-		
+
 		// Market open: 9:30 AM ET, Close: 4:00 PM ET
 		// Paris: 15:30 - 22:00
-		
+
 		// After market close
 		if hour >= 16 && minute >= 0 {
 			fmt.Println("[MARKET CLOSED] Stopping...")
 			break
 		}
-		
+
 		// Before market open
 		if hour < 9 || (hour == 9 && minute < 30) {
 			time.Sleep(30 * time.Second)
 			continue
 		}
-		
+
 		// === TRADING LOGIC ===
-		
-		// Step 1: Fetch all data concurrently (Go: 1-3s, Python: 10-30s)
+
+		// Step 1: Fetch the latest bar for every symbol on every session,
+		// concurrently (Go: 1-3s, Python: 10-30s)
 		start := time.Now()
-		stockData := client.FetchAllStocks(config.Symbols)
+		bars := fetchLatestBars(sessions, config.Symbols)
 		fetchTime := time.Since(start)
-		
+
 		// Step 2: Generate signals
 		var signals []*Signal
-		for _, data := range stockData {
-			if sig := GenerateSignal(data, config.MinConfidence); sig != nil {
+		for key, bar := range bars {
+			s, ok := series[key]
+			if !ok {
+				s = NewSignalSeries(bar.Symbol)
+				series[key] = s
+			}
+			s.Update(bar.Close, bar.High, bar.Low)
+			state.UpdatePrice(bar.Symbol, bar.Close)
+
+			// Check StopLoss/TakeProfit/Exits against any open position
+			// before looking for a new entry, same as Backtester.replay.
+			if pos := state.GetPosition(bar.Symbol); pos != nil {
+				high5m := Highest(offsetSeries{&s.High, 1}, 5)
+				low5m := Lowest(offsetSeries{&s.Low, 1}, 5)
+				data := &StockData{Symbol: bar.Symbol, Price: bar.Close, High5m: high5m, Low5m: low5m, Low: bar.Low, VolumeRatio: bar.Volume}
+				if shouldExit, reason := checkExits(exits, data, pos); shouldExit {
+					closed := Trade{
+						Symbol:     bar.Symbol,
+						Direction:  pos.Direction,
+						EntryPrice: pos.EntryPrice,
+						ExitPrice:  bar.Close,
+						EntryTime:  pos.OpenedAt,
+						ExitTime:   time.Now(),
+					}
+					closed.PnL = tradePnL(closed)
+					state.ClosePosition(bar.Symbol, reason)
+					state.RecordClosedTrade(closed)
+					if config.Persistence != nil {
+						if err := config.Persistence.RecordClosedTrade(strategyName(config), closed); err != nil {
+							fmt.Printf("[persistence] recording closed trade for %s failed: %v\n", bar.Symbol, err)
+						}
+					}
+					continue
+				}
+			}
+
+			if config.Persistence != nil && config.Persistence.InCooldown(bar.Symbol, config.CooldownSeconds) {
+				continue
+			}
+
+			var sig *Signal
+			if config.Strategy == "supertrend" {
+				st, ok := strategies[key]
+				if !ok {
+					st = newConfiguredSupertrendStrategy(config)
+					strategies[key] = st
+				}
+				st.PushK(bar)
+				sig = st.GenerateSignal(bar.Symbol, config.MinConfidence)
+			} else {
+				sig = GenerateSignal(s, config)
+			}
+
+			if sig != nil {
 				signals = append(signals, sig)
+				state.RecordSignal(sig)
+				if config.Persistence != nil {
+					config.Persistence.MarkFired(bar.Symbol)
+				}
+				if state.GetPosition(bar.Symbol) == nil {
+					pos := Position{
+						Symbol:     bar.Symbol,
+						Direction:  sig.Direction,
+						EntryPrice: sig.Price,
+						OpenedAt:   sig.Timestamp,
+					}
+					state.OpenPosition(&pos)
+					if config.Persistence != nil {
+						config.Persistence.RecordOpenPosition(pos)
+					}
+				}
 			}
 		}
-		
+
 		// Step 3: Print results
-		fmt.Printf("[%s] Fetch: %.2fs | Found %d signals\n", 
+		fmt.Printf("[%s] Fetch: %.2fs | Found %d signals\n",
 			now.Format("15:04:05"), fetchTime.Seconds(), len(signals))
-		
+
 		for _, sig := range signals {
 			fmt.Printf("  >> %s %s @ $%.2f | %.0f%%\n",
 				sig.Symbol, sig.Direction, sig.Price, sig.Confidence*100)
 		}
-		
+
 		// Sleep 5 seconds between scans (Python typically does 30+ seconds)
 		time.Sleep(5 * time.Second)
 	}
 }
 
+// fetchLatestBars queries the most recent bar for every symbol across every
+// session concurrently, returning results keyed by "<session>:<symbol>" so
+// the same symbol on two sessions doesn't collide.
+func fetchLatestBars(sessions map[string]Session, symbols []string) map[string]Bar {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	result := make(map[string]Bar)
+	var wg sync.WaitGroup
+
+	for name, session := range sessions {
+		for _, symbol := range symbols {
+			wg.Add(1)
+			go func(name, symbol string, session Session) {
+				defer wg.Done()
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					bars, err := session.QueryKLines(symbol, "1m", KLineOptions{Limit: 1})
+					if err != nil || len(bars) == 0 {
+						return
+					}
+					mu.Lock()
+					result[name+":"+symbol] = bars[len(bars)-1]
+					mu.Unlock()
+				}
+			}(name, symbol, session)
+		}
+	}
+
+	wg.Wait()
+	return result
+}
+
 // =============================================================================
 // PERFORMANCE COMPARISON
 // =============================================================================
@@ -348,20 +606,47 @@ CONCLUSION:
 */
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "optimize" {
+		if err := RunOptimizeCLI(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "optimize:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	config := Config{
-		Symbols:        []string{"AAPL", "MSFT", "GOOGL", "AMZN", "NVDA", "TSLA", "META"},
-		MinConfidence:  0.55,
-		PositionSize:   0.1,
-		MaxPositions:  5,
-		StopLoss:      0.004,
-		TakeProfit:    0.006,
+		Symbols:         []string{"AAPL", "MSFT", "GOOGL", "AMZN", "NVDA", "TSLA", "META"},
+		MinConfidence:   0.55,
+		PositionSize:    0.1,
+		MaxPositions:    5,
+		StopLoss:        0.004,
+		TakeProfit:      0.006,
 		CooldownSeconds: 15,
 	}
-	
+
+	store, err := NewSQLiteStore(envOrDefault("PERSISTENCE_DB_PATH", "trading_state.db"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "persistence:", err)
+		os.Exit(1)
+	}
+	config.Persistence = NewPersistenceFacade(store, "default")
+	if err := config.Persistence.Restore(); err != nil {
+		fmt.Println("[persistence] no prior state to restore:", err)
+	}
+	config.Persistence.WatchForShutdown()
+
 	fmt.Println("Go Trading System - Starting...")
 	RunTradingSystem(config)
 }
 
+// envOrDefault returns the named environment variable, or fallback if unset.
+func envOrDefault(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
 // =============================================================================
 // IBKR INTEGRATION (Simplified)
 // =============================================================================
@@ -378,8 +663,8 @@ use Go only for the scanning/calculation part.
 */
 
 type IBKRClient struct {
-	host    string
-	port    int
+	host     string
+	port     int
 	clientId int
 }
 
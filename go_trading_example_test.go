@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+// TestGenerateSignalBreakoutDetectsPriceAboveRecentHigh reproduces the
+// look-ahead bug where high5m/low5m included the bar that had just been
+// pushed, making price > high5m definitionally impossible.
+func TestGenerateSignalBreakoutDetectsPriceAboveRecentHigh(t *testing.T) {
+	s := NewSignalSeries("TEST")
+
+	// Five flat bars establish a 100 high/low, then a clean breakout bar.
+	for i := 0; i < 5; i++ {
+		s.Update(100, 100, 100)
+	}
+	s.Update(110, 110, 110)
+
+	high5m := Highest(offsetSeries{&s.High, 1}, 5)
+	if high5m != 100 {
+		t.Fatalf("expected high5m computed from the 5 prior bars to be 100, got %v", high5m)
+	}
+
+	sig := GenerateSignal(s, Config{})
+	if sig == nil {
+		t.Fatalf("expected a signal on a clean breakout above the prior 5-bar high")
+	}
+	if sig.Direction != "LONG" {
+		t.Fatalf("expected LONG on an upward breakout, got %s", sig.Direction)
+	}
+}
@@ -0,0 +1,102 @@
+// ibkr_session.go
+// IBKR TWS/Gateway session adapter. IBKRClient was a placeholder with no
+// streaming or kline support; IBKRSession wraps it and fills in the rest of
+// the Session interface so IBKR can sit next to Binance in Config.Sessions.
+// No call reaches TWS/Gateway: IBKRClient.Connect and PlaceOrder return nil
+// without opening a socket, so order entry and kline queries return errors
+// and the streams never deliver anything.
+
+package main
+
+import "fmt"
+
+// IBKRSession holds the Host:Port of a TWS or IB Gateway instance and the
+// ClientId to identify itself with (IBKR requires a unique client id per
+// socket connection to the same gateway).
+type IBKRSession struct {
+	Host     string
+	Port     int
+	ClientId int
+
+	client *IBKRClient
+}
+
+func NewIBKRSession(host string, port int, clientId int) *IBKRSession {
+	return &IBKRSession{
+		Host:     host,
+		Port:     port,
+		ClientId: clientId,
+		client:   NewIBKRClient(host, port, clientId),
+	}
+}
+
+func (s *IBKRSession) Name() string { return "ibkr" }
+
+func (s *IBKRSession) MarketDataStream() MarketDataStream {
+	return &ibkrMarketDataStream{session: s}
+}
+
+func (s *IBKRSession) UserDataStream() UserDataStream {
+	return &ibkrUserDataStream{session: s}
+}
+
+func (s *IBKRSession) SubmitOrder(order SubmitOrder) (Order, error) {
+	if err := s.client.Connect(); err != nil {
+		return Order{}, fmt.Errorf("ibkr session: connect: %w", err)
+	}
+
+	// IBKRClient.PlaceOrder is a placeholder that never talks to TWS/Gateway
+	// (it returns nil without sending anything), so a successful return
+	// here doesn't mean an order was actually placed. Reporting Status: "SUBMITTED" in that case
+	// would be worse than an error: it tells the caller a real order is
+	// working when nothing was sent.
+	quantity := int(order.Quantity)
+	if err := s.client.PlaceOrder(order.Symbol, order.Side, quantity); err != nil {
+		return Order{}, fmt.Errorf("ibkr session: place order: %w", err)
+	}
+	return Order{}, fmt.Errorf("ibkr session: SubmitOrder not implemented in this sandbox")
+}
+
+func (s *IBKRSession) QueryAccount() (*Account, error) {
+	if err := s.client.Connect(); err != nil {
+		return nil, fmt.Errorf("ibkr session: connect: %w", err)
+	}
+	// reqAccountSummary over the socket API; omitted in this sandbox.
+	return &Account{Balances: map[string]float64{}}, nil
+}
+
+func (s *IBKRSession) QueryKLines(symbol, interval string, options KLineOptions) ([]Bar, error) {
+	if err := s.client.Connect(); err != nil {
+		return nil, fmt.Errorf("ibkr session: connect: %w", err)
+	}
+	// reqHistoricalData over the socket API; omitted in this sandbox.
+	return nil, fmt.Errorf("ibkr session: QueryKLines not implemented in this sandbox")
+}
+
+// =============================================================================
+// STREAMS
+// =============================================================================
+
+type ibkrMarketDataStream struct {
+	session *IBKRSession
+}
+
+func (m *ibkrMarketDataStream) Subscribe(symbol string) (<-chan Bar, error) {
+	ch := make(chan Bar)
+	// No socket request is made for symbol: nothing is ever sent on ch.
+	return ch, nil
+}
+
+func (m *ibkrMarketDataStream) Close() error { return nil }
+
+type ibkrUserDataStream struct {
+	session *IBKRSession
+}
+
+func (u *ibkrUserDataStream) Orders() <-chan Order {
+	ch := make(chan Order)
+	// No socket callbacks are registered: nothing is ever sent on ch.
+	return ch
+}
+
+func (u *ibkrUserDataStream) Close() error { return nil }
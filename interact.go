@@ -0,0 +1,332 @@
+// interact.go
+// Interactive command interface over Telegram/Slack: /pnl and /cumpnl
+// render PnL charts, /positions and /signals report current scan state,
+// /close force-closes a position, and /pause / /resume toggle the scanner
+// loop without a restart.
+
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// REPLY / COMMAND REGISTRY
+// =============================================================================
+
+// Reply is how a command handler talks back to whoever invoked it.
+type Reply interface {
+	Text(message string) error
+	Photo(path string, caption string) error
+}
+
+// CommandHandler implements one registered command.
+type CommandHandler func(reply Reply, args []string) error
+
+// commandSpec is a registered command plus its help text.
+type commandSpec struct {
+	help     string
+	handler  CommandHandler
+	mutating bool
+}
+
+// InteractBackend delivers inbound commands from a chat platform and wires
+// dispatch back to Interact.
+type InteractBackend interface {
+	Start(dispatch func(cmd string, args []string, reply Reply)) error
+	Stop() error
+}
+
+// Interact is a chat-platform-agnostic command router: backends decode
+// platform messages into (cmd, args, Reply) and Interact looks up and runs
+// the registered handler.
+type Interact struct {
+	backend InteractBackend
+
+	mu       sync.Mutex
+	commands map[string]commandSpec
+}
+
+func NewInteract(backend InteractBackend) *Interact {
+	return &Interact{backend: backend, commands: make(map[string]commandSpec)}
+}
+
+// RegisterCommand adds a command. mutating marks commands that change state
+// (e.g. /close, /pause). The flag is stored but not consulted when
+// dispatching: the Telegram and Slack backends gate commands behind their
+// auth flow by name, via mutatingCommand (close, pause, resume).
+func (i *Interact) RegisterCommand(cmd, help string, handler CommandHandler, mutating bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.commands[cmd] = commandSpec{help: help, handler: handler, mutating: mutating}
+}
+
+func (i *Interact) Start() error {
+	return i.backend.Start(i.dispatch)
+}
+
+func (i *Interact) Stop() error {
+	return i.backend.Stop()
+}
+
+func (i *Interact) dispatch(cmd string, args []string, reply Reply) {
+	i.mu.Lock()
+	spec, ok := i.commands[cmd]
+	i.mu.Unlock()
+
+	if !ok {
+		reply.Text(fmt.Sprintf("unknown command: %s", cmd))
+		return
+	}
+	if err := spec.handler(reply, args); err != nil {
+		reply.Text(fmt.Sprintf("error: %v", err))
+	}
+}
+
+// =============================================================================
+// RUNNER STATE
+// =============================================================================
+
+// RunnerState is the shared, thread-safe view of a running scan loop that
+// both RunTradingSystem and the interact commands touch: open positions,
+// last known prices, recent signals, and the pause flag. It implements
+// PositionManager so exit methods can read/close positions through it too.
+type RunnerState struct {
+	mu            sync.Mutex
+	positions     map[string]*Position
+	lastPrices    map[string]float64
+	recentSignals []*Signal
+	closedTrades  []Trade
+	paused        bool
+}
+
+func NewRunnerState() *RunnerState {
+	return &RunnerState{
+		positions:  make(map[string]*Position),
+		lastPrices: make(map[string]float64),
+	}
+}
+
+func (s *RunnerState) GetPosition(symbol string) *Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.positions[symbol]
+}
+
+func (s *RunnerState) ClosePosition(symbol string, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.positions[symbol]; !ok {
+		return fmt.Errorf("no open position for %s", symbol)
+	}
+	delete(s.positions, symbol)
+	return nil
+}
+
+func (s *RunnerState) OpenPosition(pos *Position) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.positions[pos.Symbol] = pos
+}
+
+func (s *RunnerState) UpdatePrice(symbol string, price float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastPrices[symbol] = price
+}
+
+func (s *RunnerState) RecordSignal(sig *Signal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recentSignals = append(s.recentSignals, sig)
+	if len(s.recentSignals) > 100 {
+		s.recentSignals = s.recentSignals[len(s.recentSignals)-100:]
+	}
+}
+
+func (s *RunnerState) RecordClosedTrade(t Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closedTrades = append(s.closedTrades, t)
+}
+
+func (s *RunnerState) IsPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+func (s *RunnerState) SetPaused(paused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = paused
+}
+
+// =============================================================================
+// BUILT-IN COMMANDS
+// =============================================================================
+
+// RegisterBuiltinCommands wires /pnl, /cumpnl, /positions, /signals,
+// /close, /pause, and /resume against state. config.Persistence, if set, is
+// threaded through to /close so a manual close is accounted for exactly
+// like an automatic StopLoss/TakeProfit/Exits close: recorded to trade
+// history and dropped from the persisted open-position set.
+func RegisterBuiltinCommands(i *Interact, state *RunnerState, config Config) {
+	i.RegisterCommand("pnl", "Render the per-trade PnL chart", func(reply Reply, args []string) error {
+		state.mu.Lock()
+		trades := append([]Trade(nil), state.closedTrades...)
+		state.mu.Unlock()
+
+		result := summarizeTrades(trades)
+		path := "/tmp/pnl.png"
+		if err := RenderPnLChart(result, path); err != nil {
+			return err
+		}
+		return reply.Photo(path, fmt.Sprintf("Per-trade PnL (%d trades)", len(trades)))
+	}, false)
+
+	i.RegisterCommand("cumpnl", "Render the cumulative PnL chart", func(reply Reply, args []string) error {
+		state.mu.Lock()
+		trades := append([]Trade(nil), state.closedTrades...)
+		state.mu.Unlock()
+
+		result := summarizeTrades(trades)
+		path := "/tmp/cumpnl.png"
+		if err := RenderCumulativePnLChart(result, path); err != nil {
+			return err
+		}
+		return reply.Photo(path, fmt.Sprintf("Cumulative PnL: %.2f", result.TotalPnL))
+	}, false)
+
+	i.RegisterCommand("positions", "List open positions with unrealized PnL", func(reply Reply, args []string) error {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		if len(state.positions) == 0 {
+			return reply.Text("no open positions")
+		}
+		msg := ""
+		for symbol, pos := range state.positions {
+			price := state.lastPrices[symbol]
+			msg += fmt.Sprintf("%s %s @ %.2f | unrealized %.2f%%\n", symbol, pos.Direction, pos.EntryPrice, pos.favorableMove(price)*100)
+		}
+		return reply.Text(msg)
+	}, false)
+
+	i.RegisterCommand("signals", "Show the last N generated signals (default 10)", func(reply Reply, args []string) error {
+		n := 10
+		if len(args) > 0 {
+			fmt.Sscanf(args[0], "%d", &n)
+		}
+
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		signals := state.recentSignals
+		if len(signals) > n {
+			signals = signals[len(signals)-n:]
+		}
+		if len(signals) == 0 {
+			return reply.Text("no signals yet")
+		}
+		msg := ""
+		for _, sig := range signals {
+			msg += fmt.Sprintf("%s %s @ %.2f | %.0f%%\n", sig.Symbol, sig.Direction, sig.Price, sig.Confidence*100)
+		}
+		return reply.Text(msg)
+	}, false)
+
+	i.RegisterCommand("close", "Force-close a position: /close SYMBOL", func(reply Reply, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: /close SYMBOL")
+		}
+		symbol := args[0]
+		pos := state.GetPosition(symbol)
+		if pos == nil {
+			return fmt.Errorf("no open position for %s", symbol)
+		}
+
+		state.mu.Lock()
+		exitPrice := state.lastPrices[symbol]
+		state.mu.Unlock()
+
+		if err := state.ClosePosition(symbol, "manual close via interact"); err != nil {
+			return err
+		}
+
+		// Account for a manual close exactly like an automatic
+		// StopLoss/TakeProfit/Exits close: recorded to trade history and
+		// dropped from the persisted open-position set, so a restart
+		// doesn't resurrect a position the user explicitly closed.
+		closed := Trade{
+			Symbol:     symbol,
+			Direction:  pos.Direction,
+			EntryPrice: pos.EntryPrice,
+			ExitPrice:  exitPrice,
+			EntryTime:  pos.OpenedAt,
+			ExitTime:   time.Now(),
+		}
+		closed.PnL = tradePnL(closed)
+		state.RecordClosedTrade(closed)
+		if config.Persistence != nil {
+			if err := config.Persistence.RecordClosedTrade(strategyName(config), closed); err != nil {
+				fmt.Printf("[persistence] recording closed trade for %s failed: %v\n", symbol, err)
+			}
+		}
+		return reply.Text(fmt.Sprintf("closed %s", symbol))
+	}, true)
+
+	i.RegisterCommand("pause", "Pause the scanner loop", func(reply Reply, args []string) error {
+		state.SetPaused(true)
+		return reply.Text("scanner paused")
+	}, true)
+
+	i.RegisterCommand("resume", "Resume the scanner loop", func(reply Reply, args []string) error {
+		state.SetPaused(false)
+		return reply.Text("scanner resumed")
+	}, true)
+}
+
+// NewInteractFromConfig builds the configured Telegram or Slack backend and
+// wires it up to state's built-in commands. Returns nil, nil if no backend
+// is configured.
+func NewInteractFromConfig(config Config, state *RunnerState) (*Interact, error) {
+	var backend InteractBackend
+
+	switch config.InteractBackend {
+	case "":
+		return nil, nil
+	case "telegram":
+		tg, err := NewTelegramBackend(config.TelegramToken)
+		if err != nil {
+			return nil, err
+		}
+		backend = tg
+	case "slack":
+		sl, err := NewSlackBackend(config.SlackBotToken, config.SlackChannel)
+		if err != nil {
+			return nil, err
+		}
+		backend = sl
+	default:
+		return nil, fmt.Errorf("interact: unknown backend %q", config.InteractBackend)
+	}
+
+	i := NewInteract(backend)
+	RegisterBuiltinCommands(i, state, config)
+	return i, nil
+}
+
+// generateOTP returns a 6-digit one-time code used by TelegramBackend to
+// gate mutating commands to the bot owner.
+func generateOTP() (string, error) {
+	var b [3]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	code := (int(b[0])<<16 | int(b[1])<<8 | int(b[2])) % 1000000
+	return fmt.Sprintf("%06d", code), nil
+}
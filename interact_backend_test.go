@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeReply records replies without making any network call, for testing
+// command handlers that talk back through the Reply interface.
+type fakeReply struct {
+	texts []string
+}
+
+func (f *fakeReply) Text(message string) error {
+	f.texts = append(f.texts, message)
+	return nil
+}
+
+func (f *fakeReply) Photo(path string, caption string) error { return nil }
+
+func TestMutatingCommandGatesCloseePauseResume(t *testing.T) {
+	for _, cmd := range []string{"close", "pause", "resume"} {
+		if !mutatingCommand(cmd) {
+			t.Errorf("expected %q to be mutating", cmd)
+		}
+	}
+	for _, cmd := range []string{"pnl", "cumpnl", "positions", "signals"} {
+		if mutatingCommand(cmd) {
+			t.Errorf("expected %q not to be mutating", cmd)
+		}
+	}
+}
+
+func TestSlackBackendHandleAuthRequiresCorrectOTP(t *testing.T) {
+	b := &SlackBackend{otp: "123456"}
+	reply := &fakeReply{}
+
+	b.handleAuth("U1", []string{"000000"}, reply)
+	if b.authorized {
+		t.Fatalf("expected an incorrect code not to authorize")
+	}
+
+	b.handleAuth("U1", []string{"123456"}, reply)
+	if !b.authorized || b.ownerUserID != "U1" {
+		t.Fatalf("expected the correct code to authorize U1, got authorized=%v owner=%q", b.authorized, b.ownerUserID)
+	}
+}
+
+// TestSlackBackendNextEventParsesConversationsHistory reproduces the bug
+// where nextEvent was a hardcoded stub and could never receive a command:
+// it points nextEvent at a fake conversations.history and checks the oldest
+// unseen, non-bot message is returned and the cursor advances past it.
+func TestSlackBackendNextEventParsesConversationsHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// conversations.history returns newest-first.
+		json.NewEncoder(w).Encode(slackHistoryResponse{
+			OK: true,
+			Messages: []slackHistoryMessage{
+				{User: "U1", Text: "/pnl", Ts: "2.000000"},
+				{BotID: "B1", Text: "acknowledged", Ts: "1.500000"},
+				{User: "U1", Text: "/auth 123456", Ts: "1.000000"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	b := &SlackBackend{
+		Channel: "C1",
+		client:  http.DefaultClient,
+		apiBase: server.URL,
+		lastTS:  "0.000000",
+	}
+
+	userID, text := b.nextEvent()
+	if userID != "U1" || text != "/auth 123456" {
+		t.Fatalf("expected the oldest message (/auth 123456), got userID=%q text=%q", userID, text)
+	}
+	if b.lastTS != "1.000000" {
+		t.Fatalf("expected the cursor to advance to 1.000000, got %q", b.lastTS)
+	}
+}
+
+// TestSlackBackendNextEventIgnoresItsOwnMessages checks the oldest-message
+// pick skips over nothing special but still correctly reports bot_id
+// messages as empty so listen() doesn't try to dispatch the bot's own
+// replies as commands.
+func TestSlackBackendNextEventIgnoresItsOwnMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(slackHistoryResponse{
+			OK: true,
+			Messages: []slackHistoryMessage{
+				{BotID: "B1", Text: "acknowledged", Ts: "1.000000"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	b := &SlackBackend{
+		Channel: "C1",
+		client:  http.DefaultClient,
+		apiBase: server.URL,
+		lastTS:  "0.000000",
+	}
+
+	userID, text := b.nextEvent()
+	if userID != "" || text != "" {
+		t.Fatalf("expected a bot_id message to be ignored, got userID=%q text=%q", userID, text)
+	}
+	if b.lastTS != "1.000000" {
+		t.Fatalf("expected the cursor to still advance past the ignored message, got %q", b.lastTS)
+	}
+}
+
+// TestCloseCommandAccountsThroughPersistence reproduces the bug where
+// /close only called state.ClosePosition: a manually-closed trade never
+// appeared in /pnl history, and the symbol was never dropped from
+// PersistenceFacade.State.Positions, so a restart would resurrect a
+// position the user explicitly closed. /close must go through the same
+// accounting as the automatic StopLoss/TakeProfit/Exits path.
+func TestCloseCommandAccountsThroughPersistence(t *testing.T) {
+	state := NewRunnerState()
+	state.OpenPosition(&Position{Symbol: "AAPL", Direction: "LONG", EntryPrice: 100})
+	state.UpdatePrice("AAPL", 110)
+
+	store := &recordingStore{}
+	persistence := NewPersistenceFacade(store, "test")
+	persistence.RecordOpenPosition(Position{Symbol: "AAPL", Direction: "LONG", EntryPrice: 100})
+
+	i := NewInteract(&noopBackend{})
+	RegisterBuiltinCommands(i, state, Config{Persistence: persistence})
+
+	reply := &fakeReply{}
+	i.dispatch("close", []string{"AAPL"}, reply)
+
+	if state.GetPosition("AAPL") != nil {
+		t.Fatalf("expected /close to remove the in-memory position")
+	}
+	if _, ok := persistence.OpenPositions()["AAPL"]; ok {
+		t.Fatalf("expected /close to drop AAPL from the persisted open-position set")
+	}
+	if len(store.recorded) != 1 || store.recorded[0].Symbol != "AAPL" || store.recorded[0].ExitPrice != 110 {
+		t.Fatalf("expected /close to record a closed trade at the last known price, got %+v", store.recorded)
+	}
+}
+
+// noopBackend satisfies InteractBackend without ever delivering an event,
+// for tests that only need RegisterBuiltinCommands' handlers, not a live
+// backend loop.
+type noopBackend struct{}
+
+func (noopBackend) Start(dispatch func(cmd string, args []string, reply Reply)) error { return nil }
+func (noopBackend) Stop() error                                                       { return nil }
+
+func TestTelegramBackendHandleAuthRequiresCorrectOTP(t *testing.T) {
+	b := &TelegramBackend{otp: "654321"}
+	reply := &fakeReply{}
+
+	b.handleAuth(42, []string{"000000"}, reply)
+	if b.authorized {
+		t.Fatalf("expected an incorrect code not to authorize")
+	}
+
+	b.handleAuth(42, []string{"654321"}, reply)
+	if !b.authorized || b.ownerChatID != 42 {
+		t.Fatalf("expected the correct code to authorize chat 42, got authorized=%v owner=%d", b.authorized, b.ownerChatID)
+	}
+}
@@ -0,0 +1,438 @@
+// optimizer.go
+// Grid search and walk-forward parameter optimization over the backtester.
+// Evaluates the Cartesian product of a parameter template concurrently
+// through a worker pool, reports the Pareto front on (Sharpe, MaxDrawdown,
+// TotalReturn), and persists progress to a Store so long runs can resume.
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// PARAMETER SPACE
+// =============================================================================
+
+// ParamRange describes one tunable parameter's sweep: either a continuous
+// {Min,Max,Step} range or an explicit discrete Values list.
+type ParamRange struct {
+	Name   string
+	Min    float64
+	Max    float64
+	Step   float64
+	Values []float64 // if non-empty, takes precedence over Min/Max/Step
+}
+
+func (p ParamRange) values() ([]float64, error) {
+	if len(p.Values) > 0 {
+		return p.Values, nil
+	}
+	if p.Step <= 0 {
+		return nil, fmt.Errorf("optimizer: param %q: Step must be > 0 when Min/Max is used (got %v)", p.Name, p.Step)
+	}
+	var values []float64
+	for v := p.Min; v <= p.Max+1e-9; v += p.Step {
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// ParamSet is one point in the Cartesian product of a set of ParamRanges,
+// keyed by parameter name.
+type ParamSet map[string]float64
+
+// paramKey returns a stable string key for a ParamSet, used to dedupe
+// already-completed points when resuming.
+func paramKey(p ParamSet) string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s=%.6f;", name, p[name])
+	}
+	return sb.String()
+}
+
+func cartesianProduct(params []ParamRange) ([]ParamSet, error) {
+	sets := []ParamSet{{}}
+	for _, p := range params {
+		values, err := p.values()
+		if err != nil {
+			return nil, err
+		}
+		var next []ParamSet
+		for _, v := range values {
+			for _, s := range sets {
+				clone := make(ParamSet, len(s)+1)
+				for k, vv := range s {
+					clone[k] = vv
+				}
+				clone[p.Name] = v
+				next = append(next, clone)
+			}
+		}
+		sets = next
+	}
+	return sets, nil
+}
+
+// applyParams overlays a ParamSet's values onto the backtest template,
+// matching the tunables GenerateSignal and Config expose.
+func applyParams(template BacktestConfig, params ParamSet) BacktestConfig {
+	cfg := template
+	if v, ok := params["MinConfidence"]; ok {
+		cfg.MinConfidence = v
+	}
+	if v, ok := params["StopLoss"]; ok {
+		cfg.StopLoss = v
+	}
+	if v, ok := params["TakeProfit"]; ok {
+		cfg.TakeProfit = v
+	}
+	if v, ok := params["Fee"]; ok {
+		cfg.Fee = v
+	}
+	if v, ok := params["Slippage"]; ok {
+		cfg.Slippage = v
+	}
+	if v, ok := params["MomentumThreshold"]; ok {
+		cfg.MomentumThreshold = v
+	}
+	if v, ok := params["RSIOversold"]; ok {
+		cfg.RSIOversold = v
+	}
+	if v, ok := params["RSIOverbought"]; ok {
+		cfg.RSIOverbought = v
+	}
+	if v, ok := params["BreakoutWindow"]; ok {
+		cfg.BreakoutWindow = int(v)
+	}
+	return cfg
+}
+
+// =============================================================================
+// GRID SEARCH
+// =============================================================================
+
+// OptimizeResult is one evaluated parameter point.
+type OptimizeResult struct {
+	Params      ParamSet
+	Sharpe      float64
+	MaxDrawdown float64
+	TotalReturn float64
+}
+
+// OptimizeConfig names the parameters to sweep and the backtest window each
+// point is evaluated over.
+type OptimizeConfig struct {
+	Template   BacktestConfig
+	Params     []ParamRange
+	Workers    int
+	Store      Store
+	ResultsKey string
+}
+
+// RunGridSearch evaluates every point in the Cartesian product of
+// opt.Params concurrently across a worker pool, backtesting opt.Template
+// with each point's values overlaid. Points already present under
+// opt.ResultsKey in opt.Store are skipped, so a killed run resumes instead
+// of starting over.
+func RunGridSearch(opt OptimizeConfig, source HistoricalDataSource) ([]OptimizeResult, error) {
+	points, err := cartesianProduct(opt.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []OptimizeResult
+	done := make(map[string]bool)
+	if opt.Store != nil && opt.ResultsKey != "" {
+		var previous []OptimizeResult
+		if err := opt.Store.Load(opt.ResultsKey, &previous); err == nil {
+			results = previous
+			for _, r := range previous {
+				done[paramKey(r.Params)] = true
+			}
+		}
+	}
+
+	var pending []ParamSet
+	for _, p := range points {
+		if !done[paramKey(p)] {
+			pending = append(pending, p)
+		}
+	}
+
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan ParamSet)
+	resultsCh := make(chan OptimizeResult)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for params := range jobs {
+				cfg := applyParams(opt.Template, params)
+				bt := NewBacktester(cfg, source)
+				result, err := bt.Run()
+				if err != nil {
+					continue
+				}
+				resultsCh <- OptimizeResult{
+					Params:      params,
+					Sharpe:      result.Sharpe,
+					MaxDrawdown: result.MaxDrawdown,
+					TotalReturn: result.TotalPnL,
+				}
+			}
+		}()
+	}
+
+	go func() {
+		for _, p := range pending {
+			jobs <- p
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	var mu sync.Mutex
+	for r := range resultsCh {
+		mu.Lock()
+		results = append(results, r)
+		n := len(results)
+		mu.Unlock()
+
+		if opt.Store != nil && opt.ResultsKey != "" && n%5 == 0 {
+			mu.Lock()
+			snapshot := append([]OptimizeResult(nil), results...)
+			mu.Unlock()
+			if err := opt.Store.Save(opt.ResultsKey, snapshot); err != nil {
+				return results, fmt.Errorf("optimizer: persisting progress: %w", err)
+			}
+		}
+	}
+
+	if opt.Store != nil && opt.ResultsKey != "" {
+		if err := opt.Store.Save(opt.ResultsKey, results); err != nil {
+			return results, fmt.Errorf("optimizer: persisting final results: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
+// ParetoFront returns the results not dominated by any other, on
+// (Sharpe, MaxDrawdown, TotalReturn) — higher Sharpe and TotalReturn are
+// better, lower MaxDrawdown is better.
+func ParetoFront(results []OptimizeResult) []OptimizeResult {
+	var front []OptimizeResult
+	for i, a := range results {
+		dominated := false
+		for j, b := range results {
+			if i != j && dominates(b, a) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, a)
+		}
+	}
+	return front
+}
+
+func dominates(a, b OptimizeResult) bool {
+	betterOrEqual := a.Sharpe >= b.Sharpe && a.MaxDrawdown <= b.MaxDrawdown && a.TotalReturn >= b.TotalReturn
+	strictlyBetter := a.Sharpe > b.Sharpe || a.MaxDrawdown < b.MaxDrawdown || a.TotalReturn > b.TotalReturn
+	return betterOrEqual && strictlyBetter
+}
+
+// =============================================================================
+// WALK-FORWARD VALIDATION
+// =============================================================================
+
+// WalkForwardFold is one rolling train/test split of the optimization
+// window.
+type WalkForwardFold struct {
+	TrainStart, TrainEnd time.Time
+	TestStart, TestEnd   time.Time
+}
+
+// WalkForwardResult is a fold's winning in-sample parameters and their
+// out-of-sample performance; a wide gap between InSample and OutSample
+// Sharpe is the signature of overfitting.
+type WalkForwardResult struct {
+	Fold       WalkForwardFold
+	BestParams ParamSet
+	InSample   OptimizeResult
+	OutSample  OptimizeResult
+}
+
+// RunWalkForward splits [start, end] into `folds` rolling windows (70%
+// train / 30% test each), grid-searches each fold's train window,
+// evaluates the winning params on that fold's test window, and returns
+// every fold's in/out-of-sample metrics.
+func RunWalkForward(opt OptimizeConfig, source HistoricalDataSource, start, end time.Time, folds int) ([]WalkForwardResult, error) {
+	if folds <= 0 {
+		return nil, fmt.Errorf("optimizer: folds must be positive")
+	}
+
+	foldSpan := end.Sub(start) / time.Duration(folds)
+	trainSpan := foldSpan * 7 / 10
+	testSpan := foldSpan - trainSpan
+
+	var results []WalkForwardResult
+	for i := 0; i < folds; i++ {
+		foldStart := start.Add(time.Duration(i) * foldSpan)
+		trainStart, trainEnd := foldStart, foldStart.Add(trainSpan)
+		testStart, testEnd := trainEnd, trainEnd.Add(testSpan)
+
+		trainOpt := opt
+		trainOpt.Template.StartTime, trainOpt.Template.EndTime = trainStart, trainEnd
+		trainOpt.ResultsKey = fmt.Sprintf("%s:fold%d", opt.ResultsKey, i)
+
+		trainResults, err := RunGridSearch(trainOpt, source)
+		if err != nil {
+			return results, err
+		}
+		if len(trainResults) == 0 {
+			continue
+		}
+		best := bestBySharpe(trainResults)
+
+		testCfg := applyParams(opt.Template, best.Params)
+		testCfg.StartTime, testCfg.EndTime = testStart, testEnd
+		testResult, err := NewBacktester(testCfg, source).Run()
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, WalkForwardResult{
+			Fold:       WalkForwardFold{TrainStart: trainStart, TrainEnd: trainEnd, TestStart: testStart, TestEnd: testEnd},
+			BestParams: best.Params,
+			InSample:   best,
+			OutSample: OptimizeResult{
+				Params:      best.Params,
+				Sharpe:      testResult.Sharpe,
+				MaxDrawdown: testResult.MaxDrawdown,
+				TotalReturn: testResult.TotalPnL,
+			},
+		})
+	}
+
+	if opt.Store != nil && opt.ResultsKey != "" {
+		if err := opt.Store.Save(opt.ResultsKey+":walkforward", results); err != nil {
+			return results, fmt.Errorf("optimizer: persisting walk-forward results: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
+func bestBySharpe(results []OptimizeResult) OptimizeResult {
+	best := results[0]
+	for _, r := range results[1:] {
+		if r.Sharpe > best.Sharpe {
+			best = r
+		}
+	}
+	return best
+}
+
+// =============================================================================
+// HEATMAP
+// =============================================================================
+
+type heatmapKey struct{ x, y float64 }
+
+// RenderHeatmap renders a Sharpe heatmap over two parameters to path,
+// taking the best Sharpe across all other parameters for each (x, y) cell.
+func RenderHeatmap(results []OptimizeResult, xParam, yParam, path string) error {
+	best := make(map[heatmapKey]float64)
+	seenX, seenY := make(map[float64]bool), make(map[float64]bool)
+
+	for _, r := range results {
+		key := heatmapKey{r.Params[xParam], r.Params[yParam]}
+		if v, ok := best[key]; !ok || r.Sharpe > v {
+			best[key] = r.Sharpe
+		}
+		seenX[key.x] = true
+		seenY[key.y] = true
+	}
+
+	xs := sortedKeys(seenX)
+	ys := sortedKeys(seenY)
+	if len(xs) == 0 || len(ys) == 0 {
+		return fmt.Errorf("optimizer: no results vary %s/%s", xParam, yParam)
+	}
+
+	minSharpe, maxSharpe := math.Inf(1), math.Inf(-1)
+	for _, v := range best {
+		minSharpe = math.Min(minSharpe, v)
+		maxSharpe = math.Max(maxSharpe, v)
+	}
+
+	const cellSize = 32
+	img := image.NewRGBA(image.Rect(0, 0, len(xs)*cellSize, len(ys)*cellSize))
+	for xi, x := range xs {
+		for yi, y := range ys {
+			col := color.RGBA{200, 200, 200, 255}
+			if v, ok := best[heatmapKey{x, y}]; ok {
+				col = sharpeColor(v, minSharpe, maxSharpe)
+			}
+			rect := image.Rect(xi*cellSize, (len(ys)-1-yi)*cellSize, (xi+1)*cellSize, (len(ys)-yi)*cellSize)
+			draw.Draw(img, rect, &image.Uniform{C: col}, image.Point{}, draw.Src)
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
+}
+
+func sortedKeys(set map[float64]bool) []float64 {
+	keys := make([]float64, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	return keys
+}
+
+// sharpeColor maps a Sharpe value linearly onto a red (worst) to blue
+// (best) gradient.
+func sharpeColor(v, min, max float64) color.RGBA {
+	if max == min {
+		return color.RGBA{R: 128, G: 128, B: 255, A: 255}
+	}
+	t := (v - min) / (max - min)
+	return color.RGBA{R: uint8(255 * (1 - t)), G: 0, B: uint8(255 * t), A: 255}
+}
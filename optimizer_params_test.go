@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestApplyParamsSweepsSignalThresholds(t *testing.T) {
+	template := BacktestConfig{}
+
+	cfg := applyParams(template, ParamSet{
+		"MomentumThreshold": 0.01,
+		"RSIOversold":       20,
+		"RSIOverbought":     80,
+		"BreakoutWindow":    10,
+	})
+
+	if cfg.MomentumThreshold != 0.01 {
+		t.Fatalf("expected MomentumThreshold 0.01, got %v", cfg.MomentumThreshold)
+	}
+	if cfg.RSIOversold != 20 || cfg.RSIOverbought != 80 {
+		t.Fatalf("expected RSIOversold/RSIOverbought 20/80, got %v/%v", cfg.RSIOversold, cfg.RSIOverbought)
+	}
+	if cfg.BreakoutWindow != 10 {
+		t.Fatalf("expected BreakoutWindow 10, got %v", cfg.BreakoutWindow)
+	}
+}
+
+// TestParamRangeValuesRejectsZeroStep guards against a template typo like
+// {"Min": 1, "Max": 2} with Step omitted, which previously looped forever
+// since v += 0 never advances past Max.
+func TestParamRangeValuesRejectsZeroStep(t *testing.T) {
+	_, err := ParamRange{Name: "StopLoss", Min: 1, Max: 2}.values()
+	if err == nil {
+		t.Fatal("expected an error for Min != Max with Step == 0, got nil")
+	}
+}
+
+func TestCartesianProductPropagatesZeroStepError(t *testing.T) {
+	if _, err := cartesianProduct([]ParamRange{{Name: "StopLoss", Min: 1, Max: 2}}); err == nil {
+		t.Fatal("expected cartesianProduct to surface the bad ParamRange instead of hanging")
+	}
+}
+
+// TestSignalThresholdsHonorsConfiguredBreakoutWindow confirms sweeping
+// BreakoutWindow actually changes the window GenerateSignal computes
+// high5m/low5m over, i.e. it isn't a silently ignored grid point.
+func TestSignalThresholdsHonorsConfiguredBreakoutWindow(t *testing.T) {
+	s := NewSignalSeries("TEST")
+	s.Update(150, 150, 150) // an early spike that only a wide window still sees
+	for i := 0; i < 6; i++ {
+		s.Update(90, 90, 90)
+	}
+	s.Update(105, 105, 105) // current bar
+
+	_, _, _, narrowWindow := signalThresholds(Config{BreakoutWindow: 3})
+	_, _, _, wideWindow := signalThresholds(Config{BreakoutWindow: 20})
+
+	narrowHigh := Highest(offsetSeries{&s.High, 1}, narrowWindow)
+	wideHigh := Highest(offsetSeries{&s.High, 1}, wideWindow)
+
+	if narrowHigh != 90 {
+		t.Fatalf("expected a 3-bar window to miss the early spike (high 90), got %v", narrowHigh)
+	}
+	if wideHigh != 150 {
+		t.Fatalf("expected a 20-bar window to still see the early spike (high 150), got %v", wideHigh)
+	}
+}
@@ -0,0 +1,307 @@
+// persistence.go
+// Store interface plus Redis and SQLite backends for open positions,
+// cooldowns, and closed-trade history, so a restart doesn't lose state or
+// double-fire signals inside CooldownSeconds.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// =============================================================================
+// STORE
+// =============================================================================
+
+// Store persists arbitrary JSON-serializable values under a string key.
+type Store interface {
+	Load(key string, v any) error
+	Save(key string, v any) error
+	Sync(ctx context.Context) error
+}
+
+// =============================================================================
+// REDIS STORE
+// =============================================================================
+
+// RedisStore persists state as JSON blobs under plain string keys.
+type RedisStore struct {
+	Host string
+	Port int
+	DB   int
+
+	client *redis.Client
+}
+
+func NewRedisStore(host string, port, db int) *RedisStore {
+	return &RedisStore{
+		Host: host,
+		Port: port,
+		DB:   db,
+		client: redis.NewClient(&redis.Options{
+			Addr: fmt.Sprintf("%s:%d", host, port),
+			DB:   db,
+		}),
+	}
+}
+
+func (s *RedisStore) Load(key string, v any) error {
+	data, err := s.client.Get(context.Background(), key).Bytes()
+	if err != nil {
+		return fmt.Errorf("redis store: load %s: %w", key, err)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (s *RedisStore) Save(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(context.Background(), key, data, 0).Err(); err != nil {
+		return fmt.Errorf("redis store: save %s: %w", key, err)
+	}
+	return nil
+}
+
+// Sync is a no-op: every Save already round-trips to Redis.
+func (s *RedisStore) Sync(ctx context.Context) error { return nil }
+
+// =============================================================================
+// SQLITE STORE
+// =============================================================================
+
+// sqliteSchema creates the key/value table used by Load/Save plus the
+// positions table strategies' closed trades are recorded into. The
+// composite index keeps PnL queries over (strategy, symbol, closed_at) fast.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS kv (
+	key        TEXT PRIMARY KEY,
+	value      TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS positions (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	strategy    TEXT NOT NULL,
+	symbol      TEXT NOT NULL,
+	direction   TEXT NOT NULL,
+	entry_price REAL NOT NULL,
+	exit_price  REAL,
+	opened_at   TIMESTAMP NOT NULL,
+	closed_at   TIMESTAMP,
+	pnl         REAL
+);
+
+CREATE INDEX IF NOT EXISTS idx_positions_strategy_symbol_closed
+	ON positions (strategy, symbol, closed_at);
+`
+
+// SQLiteStore persists state in a key/value table and closed trades in a
+// dedicated positions table, in a single SQLite file at Path.
+type SQLiteStore struct {
+	Path string
+
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite store: open %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("sqlite store: migrate: %w", err)
+	}
+	return &SQLiteStore{Path: path, db: db}, nil
+}
+
+func (s *SQLiteStore) Load(key string, v any) error {
+	var raw string
+	row := s.db.QueryRow(`SELECT value FROM kv WHERE key = ?`, key)
+	if err := row.Scan(&raw); err != nil {
+		return fmt.Errorf("sqlite store: load %s: %w", key, err)
+	}
+	return json.Unmarshal([]byte(raw), v)
+}
+
+func (s *SQLiteStore) Save(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO kv (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		key, string(data), time.Now())
+	if err != nil {
+		return fmt.Errorf("sqlite store: save %s: %w", key, err)
+	}
+	return nil
+}
+
+// Sync is a no-op: sqlite commits each Exec in its own transaction.
+func (s *SQLiteStore) Sync(ctx context.Context) error { return nil }
+
+// TradeRecorder is implemented by Store backends that can also track
+// closed-trade history in a queryable form. Only SQLiteStore does today;
+// PersistenceFacade.RecordClosedTrade type-asserts for it so closed trades
+// still land in the durable positions table, not just the JSON blob.
+type TradeRecorder interface {
+	RecordTrade(strategy string, t Trade) error
+}
+
+// RecordTrade appends a closed trade to the positions table for PnL
+// reporting, keyed by the strategy name that produced it.
+func (s *SQLiteStore) RecordTrade(strategy string, t Trade) error {
+	_, err := s.db.Exec(`
+		INSERT INTO positions (strategy, symbol, direction, entry_price, exit_price, opened_at, closed_at, pnl)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		strategy, t.Symbol, t.Direction, t.EntryPrice, t.ExitPrice, t.EntryTime, t.ExitTime, t.PnL)
+	if err != nil {
+		return fmt.Errorf("sqlite store: record trade: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// PERSISTENCE FACADE
+// =============================================================================
+
+// PersistenceState is everything a running strategy needs restored after a
+// restart: open positions (so they aren't forgotten) and per-symbol
+// cooldown timestamps (so CooldownSeconds isn't reset to zero and
+// double-fire a signal right after restart).
+//
+// Descoped: the original ask for this layer also covered persisting daily
+// fee/volume budgets. Nothing in this codebase enforces such a budget (no
+// config field, no check site that would consult it), so there was no
+// behavior to restore across a restart and no caller to wire one up to.
+// Left out here as a deliberate cut rather than an oversight; add
+// Positions/Cooldowns-style fields once a budget enforcement path exists.
+type PersistenceState struct {
+	Positions map[string]Position
+	Cooldowns map[string]time.Time
+}
+
+// PersistenceFacade is embedded by a strategy/runner to get automatic state
+// snapshotting on graceful shutdown, mirroring bbgo's persistence facade.
+// mu guards State: WatchForShutdown's goroutine calls Snapshot concurrently
+// with the scan loop's MarkFired/InCooldown calls, and State's maps aren't
+// safe for concurrent access on their own.
+type PersistenceFacade struct {
+	Store Store
+	Key   string
+
+	mu    sync.Mutex
+	State PersistenceState
+}
+
+func NewPersistenceFacade(store Store, key string) *PersistenceFacade {
+	return &PersistenceFacade{
+		Store: store,
+		Key:   key,
+		State: PersistenceState{
+			Positions: make(map[string]Position),
+			Cooldowns: make(map[string]time.Time),
+		},
+	}
+}
+
+// Restore loads previously snapshotted state, if any exists under Key.
+func (f *PersistenceFacade) Restore() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Store.Load(f.Key, &f.State)
+}
+
+// Snapshot saves the current state and flushes the backend.
+func (f *PersistenceFacade) Snapshot() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.Store.Save(f.Key, &f.State); err != nil {
+		return err
+	}
+	return f.Store.Sync(context.Background())
+}
+
+// InCooldown reports whether symbol fired a signal within the last
+// cooldownSeconds, guarding against double-firing across restarts.
+func (f *PersistenceFacade) InCooldown(symbol string, cooldownSeconds int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	last, ok := f.State.Cooldowns[symbol]
+	if !ok {
+		return false
+	}
+	return time.Since(last) < time.Duration(cooldownSeconds)*time.Second
+}
+
+// MarkFired records that symbol just fired a signal, starting its cooldown.
+func (f *PersistenceFacade) MarkFired(symbol string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.State.Cooldowns[symbol] = time.Now()
+}
+
+// OpenPositions returns a copy of the persisted open positions, restored by
+// Restore, so RunTradingSystem can reseed RunnerState after a restart
+// instead of forgetting every position that was open when it died.
+func (f *PersistenceFacade) OpenPositions() map[string]Position {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[string]Position, len(f.State.Positions))
+	for symbol, pos := range f.State.Positions {
+		out[symbol] = pos
+	}
+	return out
+}
+
+// RecordOpenPosition persists pos so it survives a restart until it's
+// closed.
+func (f *PersistenceFacade) RecordOpenPosition(pos Position) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.State.Positions[pos.Symbol] = pos
+}
+
+// RecordClosedTrade drops t.Symbol from the persisted open-position set and,
+// if Store also implements TradeRecorder (SQLiteStore), records t into the
+// queryable closed-trade history.
+func (f *PersistenceFacade) RecordClosedTrade(strategy string, t Trade) error {
+	f.mu.Lock()
+	delete(f.State.Positions, t.Symbol)
+	f.mu.Unlock()
+
+	if recorder, ok := f.Store.(TradeRecorder); ok {
+		return recorder.RecordTrade(strategy, t)
+	}
+	return nil
+}
+
+// WatchForShutdown snapshots state on SIGINT/SIGTERM so the next run resumes
+// from exactly where this process left off.
+func (f *PersistenceFacade) WatchForShutdown() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		if err := f.Snapshot(); err != nil {
+			fmt.Printf("[persistence] snapshot on shutdown failed: %v\n", err)
+		}
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// noopStore discards Save/Sync and returns nil from Load without touching
+// v, just enough to exercise
+// PersistenceFacade's locking without a real backend.
+type noopStore struct{}
+
+func (noopStore) Load(key string, v any) error   { return nil }
+func (noopStore) Save(key string, v any) error   { return nil }
+func (noopStore) Sync(ctx context.Context) error { return nil }
+
+// recordingStore is a noopStore that also remembers RecordTrade calls, so
+// tests can assert PersistenceFacade.RecordClosedTrade reaches a
+// TradeRecorder backend.
+type recordingStore struct {
+	noopStore
+	recorded []Trade
+}
+
+func (s *recordingStore) RecordTrade(strategy string, t Trade) error {
+	s.recorded = append(s.recorded, t)
+	return nil
+}
+
+func TestPersistenceFacadeSurvivesRestartViaOpenPositions(t *testing.T) {
+	f := NewPersistenceFacade(noopStore{}, "test")
+
+	pos := Position{Symbol: "AAPL", Direction: "LONG", EntryPrice: 150}
+	f.RecordOpenPosition(pos)
+
+	restored := f.OpenPositions()
+	got, ok := restored["AAPL"]
+	if !ok || got.Symbol != pos.Symbol || got.Direction != pos.Direction || got.EntryPrice != pos.EntryPrice {
+		t.Fatalf("expected OpenPositions to return the recorded position, got %+v, ok=%v", got, ok)
+	}
+}
+
+func TestPersistenceFacadeRecordClosedTradeDropsPositionAndRecords(t *testing.T) {
+	store := &recordingStore{}
+	f := NewPersistenceFacade(store, "test")
+	f.RecordOpenPosition(Position{Symbol: "AAPL", Direction: "LONG", EntryPrice: 150})
+
+	trade := Trade{Symbol: "AAPL", Direction: "LONG", EntryPrice: 150, ExitPrice: 155, PnL: 5}
+	if err := f.RecordClosedTrade("momentum", trade); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := f.OpenPositions()["AAPL"]; ok {
+		t.Fatalf("expected AAPL to no longer be an open position after RecordClosedTrade")
+	}
+	if len(store.recorded) != 1 || store.recorded[0] != trade {
+		t.Fatalf("expected the closed trade to reach the TradeRecorder, got %+v", store.recorded)
+	}
+}
+
+// TestPersistenceFacadeConcurrentSnapshotAndMarkFired reproduces the
+// concurrent map read/write between WatchForShutdown's goroutine (Snapshot)
+// and the scan loop (MarkFired): run with -race, it must not panic or be
+// flagged as a data race.
+func TestPersistenceFacadeConcurrentSnapshotAndMarkFired(t *testing.T) {
+	f := NewPersistenceFacade(noopStore{}, "test")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			f.MarkFired("AAPL")
+		}()
+		go func() {
+			defer wg.Done()
+			if err := f.Snapshot(); err != nil {
+				t.Errorf("unexpected snapshot error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
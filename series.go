@@ -0,0 +1,447 @@
+// series.go
+// Streaming indicator API. CalculateSMA/CalculateRSI/CalculateMACD
+// recomputed over the whole price slice on every call, which is quadratic
+// cost per scan. Series replaces that with O(1)-per-bar indicators that
+// keep their own state, following the c9s/bbgo redesign where Last(0) is
+// the canonical "most recent value" accessor.
+
+package main
+
+import "math"
+
+// =============================================================================
+// SERIES
+// =============================================================================
+
+// Series is a pandas-like reverse-indexed view over a growing sequence of
+// values. Last(0) is the newest value, Last(1) the one before it, and so on.
+type Series interface {
+	// Length returns how many values have been pushed so far.
+	Length() int
+	// Last returns the value i steps back from the newest, Last(0) == newest.
+	Last(i int) float64
+	// Index returns the value at absolute position i, oldest-first.
+	Index(i int) float64
+}
+
+// buffer is the append-only backing store shared by the streaming indicators
+// below; it implements Series directly.
+type buffer struct {
+	values []float64
+}
+
+func (b *buffer) push(v float64) {
+	b.values = append(b.values, v)
+}
+
+func (b *buffer) Length() int { return len(b.values) }
+
+func (b *buffer) Last(i int) float64 {
+	idx := len(b.values) - 1 - i
+	if idx < 0 || idx >= len(b.values) {
+		return 0
+	}
+	return b.values[idx]
+}
+
+func (b *buffer) Index(i int) float64 {
+	if i < 0 || i >= len(b.values) {
+		return 0
+	}
+	return b.values[i]
+}
+
+// =============================================================================
+// ARITHMETIC HELPERS
+// =============================================================================
+
+// binOpSeries lazily combines two series element-by-element, indexed from
+// the newest value backward, without materializing an intermediate slice.
+type binOpSeries struct {
+	a, b Series
+	op   func(x, y float64) float64
+}
+
+func (s binOpSeries) Length() int {
+	if s.a.Length() < s.b.Length() {
+		return s.a.Length()
+	}
+	return s.b.Length()
+}
+
+func (s binOpSeries) Last(i int) float64 { return s.op(s.a.Last(i), s.b.Last(i)) }
+
+func (s binOpSeries) Index(i int) float64 {
+	return s.op(s.a.Index(i), s.b.Index(i))
+}
+
+func Add(a, b Series) Series { return binOpSeries{a, b, func(x, y float64) float64 { return x + y }} }
+func Sub(a, b Series) Series { return binOpSeries{a, b, func(x, y float64) float64 { return x - y }} }
+func Mul(a, b Series) Series { return binOpSeries{a, b, func(x, y float64) float64 { return x * y }} }
+func Div(a, b Series) Series {
+	return binOpSeries{a, b, func(x, y float64) float64 {
+		if y == 0 {
+			return 0
+		}
+		return x / y
+	}}
+}
+
+// Stddev returns the population standard deviation of the last window
+// values (Last(0)..Last(window-1)).
+func Stddev(s Series, window int) float64 {
+	n := window
+	if s.Length() < n {
+		n = s.Length()
+	}
+	if n == 0 {
+		return 0
+	}
+
+	var mean float64
+	for i := 0; i < n; i++ {
+		mean += s.Last(i)
+	}
+	mean /= float64(n)
+
+	var variance float64
+	for i := 0; i < n; i++ {
+		d := s.Last(i) - mean
+		variance += d * d
+	}
+	return math.Sqrt(variance / float64(n))
+}
+
+// offsetSeries shifts a Series view back by offset bars, so Last(0) on the
+// view is Last(offset) on the underlying series. Used to compute a rolling
+// window (e.g. via Highest/Lowest) that excludes the most recently pushed
+// value, such as the current bar's own high/low.
+type offsetSeries struct {
+	s      Series
+	offset int
+}
+
+func (o offsetSeries) Length() int {
+	n := o.s.Length() - o.offset
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func (o offsetSeries) Last(i int) float64  { return o.s.Last(i + o.offset) }
+func (o offsetSeries) Index(i int) float64 { return o.s.Index(i) }
+
+// Highest returns the maximum of the last window values.
+func Highest(s Series, window int) float64 {
+	n := window
+	if s.Length() < n {
+		n = s.Length()
+	}
+	if n == 0 {
+		return 0
+	}
+	max := s.Last(0)
+	for i := 1; i < n; i++ {
+		if v := s.Last(i); v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// Lowest returns the minimum of the last window values.
+func Lowest(s Series, window int) float64 {
+	n := window
+	if s.Length() < n {
+		n = s.Length()
+	}
+	if n == 0 {
+		return 0
+	}
+	min := s.Last(0)
+	for i := 1; i < n; i++ {
+		if v := s.Last(i); v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// =============================================================================
+// EWMA / SMA
+// =============================================================================
+
+// EWMA is an exponentially weighted moving average updated in O(1) per tick.
+type EWMA struct {
+	Window int
+
+	buffer
+	initialized bool
+}
+
+func (e *EWMA) Update(price float64) {
+	if !e.initialized {
+		e.push(price)
+		e.initialized = true
+		return
+	}
+	multiplier := 2.0 / float64(e.Window+1)
+	e.push((price-e.Last(0))*multiplier + e.Last(0))
+}
+
+// SMA is a simple moving average over the last Window prices.
+type SMA struct {
+	Window int
+
+	buffer
+	prices []float64
+}
+
+func (s *SMA) Update(price float64) {
+	s.prices = append(s.prices, price)
+	if len(s.prices) > s.Window {
+		s.prices = s.prices[len(s.prices)-s.Window:]
+	}
+	var sum float64
+	for _, p := range s.prices {
+		sum += p
+	}
+	s.push(sum / float64(len(s.prices)))
+}
+
+// DEMA is a double exponential moving average: 2*EMA1 - EMA(EMA1), which
+// reacts faster than a plain EMA while still smoothing noise.
+type DEMA struct {
+	Window int
+
+	ema1, ema2 EWMA
+	buffer
+}
+
+func NewDEMA(window int) *DEMA {
+	return &DEMA{Window: window, ema1: EWMA{Window: window}, ema2: EWMA{Window: window}}
+}
+
+func (d *DEMA) Update(price float64) {
+	d.ema1.Update(price)
+	d.ema2.Update(d.ema1.Last(0))
+	d.push(2*d.ema1.Last(0) - d.ema2.Last(0))
+}
+
+// LinearRegressionSlope returns the least-squares slope of the last window
+// values of s (Last(0) newest), in value-per-bar units. Used as a chop
+// filter: a near-zero slope means price isn't trending either way.
+func LinearRegressionSlope(s Series, window int) float64 {
+	n := window
+	if s.Length() < n {
+		n = s.Length()
+	}
+	if n < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i := 0; i < n; i++ {
+		x := float64(i)
+		y := s.Last(n - 1 - i) // i=0 -> oldest in window, i=n-1 -> newest
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := float64(n)*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (float64(n)*sumXY - sumX*sumY) / denom
+}
+
+// =============================================================================
+// RSI
+// =============================================================================
+
+// RSI is Wilder's relative strength index, maintained with running average
+// gain/loss so each Update is O(1) instead of rescanning the price history.
+type RSI struct {
+	Window int
+
+	buffer
+	prevPrice        float64
+	hasPrev          bool
+	avgGain, avgLoss float64
+	ticks            int
+}
+
+func (r *RSI) Update(price float64) {
+	if !r.hasPrev {
+		r.prevPrice = price
+		r.hasPrev = true
+		r.push(50)
+		return
+	}
+
+	change := price - r.prevPrice
+	r.prevPrice = price
+
+	gain, loss := 0.0, 0.0
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	r.ticks++
+	if r.ticks <= r.Window {
+		r.avgGain += gain / float64(r.Window)
+		r.avgLoss += loss / float64(r.Window)
+	} else {
+		r.avgGain = (r.avgGain*float64(r.Window-1) + gain) / float64(r.Window)
+		r.avgLoss = (r.avgLoss*float64(r.Window-1) + loss) / float64(r.Window)
+	}
+
+	if r.avgLoss == 0 {
+		r.push(100)
+		return
+	}
+	rs := r.avgGain / r.avgLoss
+	r.push(100 - (100 / (1 + rs)))
+}
+
+// =============================================================================
+// MACD
+// =============================================================================
+
+// MACD tracks fast/slow EWMAs of price and a signal EWMA of the MACD line,
+// exposing the line, signal, and histogram as Series.
+type MACD struct {
+	FastWindow   int
+	SlowWindow   int
+	SignalWindow int
+
+	fast, slow, signal EWMA
+	macdLine, hist     buffer
+}
+
+func NewMACD(fastWindow, slowWindow, signalWindow int) *MACD {
+	return &MACD{
+		FastWindow:   fastWindow,
+		SlowWindow:   slowWindow,
+		SignalWindow: signalWindow,
+		fast:         EWMA{Window: fastWindow},
+		slow:         EWMA{Window: slowWindow},
+		signal:       EWMA{Window: signalWindow},
+	}
+}
+
+func (m *MACD) Update(price float64) {
+	m.fast.Update(price)
+	m.slow.Update(price)
+
+	macd := m.fast.Last(0) - m.slow.Last(0)
+	m.macdLine.push(macd)
+	m.signal.Update(macd)
+	m.hist.push(macd - m.signal.Last(0))
+}
+
+func (m *MACD) Line() Series      { return &m.macdLine }
+func (m *MACD) Signal() Series    { return &m.signal }
+func (m *MACD) Histogram() Series { return &m.hist }
+
+// =============================================================================
+// BOLLINGER BANDS
+// =============================================================================
+
+// BollingerBands wraps a streaming SMA with a K-stddev envelope.
+type BollingerBands struct {
+	Window int
+	K      float64
+
+	sma          SMA
+	upper, lower buffer
+}
+
+func NewBollingerBands(window int, k float64) *BollingerBands {
+	return &BollingerBands{Window: window, K: k, sma: SMA{Window: window}}
+}
+
+func (b *BollingerBands) Update(price float64) {
+	b.sma.Update(price)
+	stddev := stddevOfPrices(b.sma.prices)
+	mid := b.sma.Last(0)
+	b.upper.push(mid + b.K*stddev)
+	b.lower.push(mid - b.K*stddev)
+}
+
+func stddevOfPrices(prices []float64) float64 {
+	if len(prices) == 0 {
+		return 0
+	}
+	var mean float64
+	for _, p := range prices {
+		mean += p
+	}
+	mean /= float64(len(prices))
+
+	var variance float64
+	for _, p := range prices {
+		d := p - mean
+		variance += d * d
+	}
+	return math.Sqrt(variance / float64(len(prices)))
+}
+
+func (b *BollingerBands) Upper() Series  { return &b.upper }
+func (b *BollingerBands) Middle() Series { return &b.sma }
+func (b *BollingerBands) Lower() Series  { return &b.lower }
+
+// =============================================================================
+// ATR / VWAP (bar-based indicators)
+// =============================================================================
+
+// ATR is Wilder's average true range, fed whole bars via PushK.
+type ATR struct {
+	Window int
+
+	buffer
+	prevClose float64
+	hasPrev   bool
+}
+
+func (a *ATR) PushK(bar Bar) {
+	tr := bar.High - bar.Low
+	if a.hasPrev {
+		if v := math.Abs(bar.High - a.prevClose); v > tr {
+			tr = v
+		}
+		if v := math.Abs(bar.Low - a.prevClose); v > tr {
+			tr = v
+		}
+	}
+	a.prevClose = bar.Close
+	a.hasPrev = true
+
+	if a.Length() == 0 {
+		a.push(tr)
+		return
+	}
+	a.push((a.Last(0)*float64(a.Window-1) + tr) / float64(a.Window))
+}
+
+// VWAP is the cumulative volume-weighted average price since the VWAP was
+// created (callers reset by constructing a new one at session boundaries).
+type VWAP struct {
+	buffer
+	cumPV, cumVolume float64
+}
+
+func (v *VWAP) PushK(bar Bar) {
+	typical := (bar.High + bar.Low + bar.Close) / 3
+	v.cumPV += typical * bar.Volume
+	v.cumVolume += bar.Volume
+	if v.cumVolume == 0 {
+		v.push(typical)
+		return
+	}
+	v.push(v.cumPV / v.cumVolume)
+}
@@ -0,0 +1,111 @@
+// session.go
+// Exchange session abstraction. YahooFinanceClient used to be hardcoded
+// into RunTradingSystem and IBKRClient was a stub nobody actually wired in;
+// Session lets the main loop iterate over however many exchanges a config
+// names, live or paper, without rewriting the scan loop per adapter.
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// SESSION INTERFACE
+// =============================================================================
+
+// SubmitOrder describes an order to place on a session.
+type SubmitOrder struct {
+	Symbol   string
+	Side     string // "BUY" or "SELL"
+	Type     string // "MARKET" or "LIMIT"
+	Quantity float64
+	Price    float64 // ignored for MARKET orders
+}
+
+// Order is the exchange's view of a submitted order.
+type Order struct {
+	ID       string
+	Symbol   string
+	Side     string
+	Quantity float64
+	Price    float64
+	Status   string
+}
+
+// Account holds per-asset balances as reported by a session.
+type Account struct {
+	Balances map[string]float64
+}
+
+// KLineOptions narrows a QueryKLines call.
+type KLineOptions struct {
+	Limit     int
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// MarketDataStream delivers live bars for symbols the caller subscribes to.
+type MarketDataStream interface {
+	Subscribe(symbol string) (<-chan Bar, error)
+	Close() error
+}
+
+// UserDataStream delivers order/account updates for the session's account.
+type UserDataStream interface {
+	Orders() <-chan Order
+	Close() error
+}
+
+// Session is one exchange connection: market data, user data, order entry,
+// and historical kline queries. RunTradingSystem iterates a
+// map[string]Session rather than a single hardcoded client, so adding an
+// exchange or toggling paper/live is a config change, not a rewrite.
+type Session interface {
+	Name() string
+	MarketDataStream() MarketDataStream
+	UserDataStream() UserDataStream
+	SubmitOrder(order SubmitOrder) (Order, error)
+	QueryAccount() (*Account, error)
+	QueryKLines(symbol, interval string, options KLineOptions) ([]Bar, error)
+}
+
+// =============================================================================
+// YAHOO SESSION (wraps the existing REST client)
+// =============================================================================
+
+// YahooSession adapts the Yahoo chart API to the Session interface. It has
+// no order entry or streaming support, so it's suited to read-only scanning
+// sessions, same as the pre-Session main loop.
+type YahooSession struct{}
+
+func NewYahooSession() *YahooSession {
+	return &YahooSession{}
+}
+
+func (s *YahooSession) Name() string { return "yahoo" }
+
+func (s *YahooSession) MarketDataStream() MarketDataStream { return nil }
+func (s *YahooSession) UserDataStream() UserDataStream     { return nil }
+
+func (s *YahooSession) SubmitOrder(order SubmitOrder) (Order, error) {
+	return Order{}, fmt.Errorf("yahoo session: order entry not supported")
+}
+
+func (s *YahooSession) QueryAccount() (*Account, error) {
+	return nil, fmt.Errorf("yahoo session: account queries not supported")
+}
+
+func (s *YahooSession) QueryKLines(symbol, interval string, options KLineOptions) ([]Bar, error) {
+	source := NewYahooHistoricalDataSource()
+	end := options.EndTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := options.StartTime
+	if start.IsZero() {
+		start = end.Add(-24 * time.Hour)
+	}
+	return source.LoadBars(symbol, start, end, interval)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestBinanceSessionQueryKLinesParsesRESTResponse reproduces the bug where
+// QueryKLines was a stub returning only an error: it points baseRestURL at
+// a fake /api/v3/klines and checks the string OHLCV fields and millisecond
+// openTime are parsed into a Bar.
+func TestBinanceSessionQueryKLinesParsesRESTResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("symbol"); got != "BTCUSDT" {
+			t.Errorf("expected symbol=BTCUSDT in the request, got %q", got)
+		}
+		fmt.Fprint(w, `[[1499040000000,"0.01","0.02","0.005","0.015","100.0",1499040059999]]`)
+	}))
+	defer server.Close()
+
+	s := NewBinanceSession("TEST", false)
+	s.restBaseOverride = server.URL
+
+	bars, err := s.QueryKLines("BTCUSDT", "1m", KLineOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bars) != 1 {
+		t.Fatalf("expected 1 bar, got %d", len(bars))
+	}
+	bar := bars[0]
+	if bar.Symbol != "BTCUSDT" || bar.Open != 0.01 || bar.High != 0.02 || bar.Low != 0.005 || bar.Close != 0.015 || bar.Volume != 100.0 {
+		t.Fatalf("expected parsed OHLCV, got %+v", bar)
+	}
+	if bar.Time.UnixMilli() != 1499040000000 {
+		t.Fatalf("expected openTime to round-trip as the bar's Time, got %v", bar.Time)
+	}
+}
+
+// TestBinanceSessionSubmitOrderDoesNotFabricateSuccess guards against
+// SubmitOrder reporting a fake "NEW" order when no request was ever sent.
+func TestBinanceSessionSubmitOrderDoesNotFabricateSuccess(t *testing.T) {
+	s := &BinanceSession{EnvVarPrefix: "TEST", apiKey: "key", apiSecret: "secret"}
+
+	order, err := s.SubmitOrder(SubmitOrder{Symbol: "BTCUSDT", Side: "BUY", Quantity: 1})
+	if err == nil {
+		t.Fatalf("expected an error since no real request is made, got order %+v", order)
+	}
+	if order.Status != "" {
+		t.Fatalf("expected a zero-value Order on failure, got Status %q", order.Status)
+	}
+}
+
+// TestIBKRSessionSubmitOrderDoesNotFabricateSuccess guards against
+// SubmitOrder reporting a fake "SUBMITTED" order when PlaceOrder is a
+// no-op placeholder.
+func TestIBKRSessionSubmitOrderDoesNotFabricateSuccess(t *testing.T) {
+	s := NewIBKRSession("localhost", 7497, 1)
+
+	order, err := s.SubmitOrder(SubmitOrder{Symbol: "AAPL", Side: "BUY", Quantity: 1})
+	if err == nil {
+		t.Fatalf("expected an error since PlaceOrder never talks to TWS/Gateway, got order %+v", order)
+	}
+	if order.Status != "" {
+		t.Fatalf("expected a zero-value Order on failure, got Status %q", order.Status)
+	}
+}
@@ -0,0 +1,246 @@
+// slack_backend.go
+// Slack app backend for Interact, reading messages in a configured channel
+// whose first word starts with "/". Polls conversations.history the same way
+// TelegramBackend long-polls getUpdates, and mirrors its OTP auth gate:
+// only the user who proves ownership via /auth <otp> may run mutating
+// commands.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SlackBackend dispatches channel messages that start with "/" to Interact;
+// Slack slash-command and app-mention events are not handled. BotToken
+// authenticates outbound chat.postMessage/files.upload
+// calls; Channel scopes which channel the bot listens and replies in.
+type SlackBackend struct {
+	BotToken string
+	Channel  string
+
+	client       *http.Client
+	apiBase      string        // Slack Web API base URL, overridden in tests
+	pollInterval time.Duration // client-side poll interval, shortened in tests
+	lastTS       string        // conversations.history cursor: the ts of the last message we've seen
+	otp          string
+	ownerUserID  string
+	authorized   bool
+	stop         chan struct{}
+}
+
+func NewSlackBackend(botToken, channel string) (*SlackBackend, error) {
+	otp, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("[slack] owner auth code: %s (send \"/auth %s\" in %s to authorize mutating commands)\n", otp, otp, channel)
+
+	return &SlackBackend{
+		BotToken:     botToken,
+		Channel:      channel,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		apiBase:      "https://slack.com/api",
+		pollInterval: 2 * time.Second,
+		lastTS:       fmt.Sprintf("%d.000000", time.Now().Unix()), // skip history predating startup
+		otp:          otp,
+		stop:         make(chan struct{}),
+	}, nil
+}
+
+func (b *SlackBackend) Start(dispatch func(cmd string, args []string, reply Reply)) error {
+	go b.listen(dispatch)
+	return nil
+}
+
+func (b *SlackBackend) Stop() error {
+	close(b.stop)
+	return nil
+}
+
+// listen polls conversations.history and turns "/pnl", "/close AAPL", etc.
+// into dispatch calls, gating mutating commands behind the same OTP flow
+// TelegramBackend uses. nextEvent blocks for its own poll interval, so this
+// loop never busy-spins.
+func (b *SlackBackend) listen(dispatch func(cmd string, args []string, reply Reply)) {
+	for {
+		select {
+		case <-b.stop:
+			return
+		default:
+		}
+
+		userID, text := b.nextEvent()
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+			continue
+		}
+		cmd := strings.TrimPrefix(fields[0], "/")
+		args := fields[1:]
+
+		reply := &slackReply{backend: b}
+
+		if cmd == "auth" {
+			b.handleAuth(userID, args, reply)
+			continue
+		}
+
+		if mutatingCommand(cmd) && (!b.authorized || userID != b.ownerUserID) {
+			reply.Text("unauthorized: send /auth <code> first")
+			continue
+		}
+
+		dispatch(cmd, args, reply)
+	}
+}
+
+func (b *SlackBackend) handleAuth(userID string, args []string, reply Reply) {
+	if len(args) != 1 || args[0] != b.otp {
+		reply.Text("invalid code")
+		return
+	}
+	b.ownerUserID = userID
+	b.authorized = true
+	reply.Text("authorized")
+}
+
+// slackHistoryMessage is one entry from conversations.history. BotID is
+// set on messages the bot itself posted (our own replies), which nextEvent
+// must ignore so it doesn't try to dispatch its own output as a command.
+type slackHistoryMessage struct {
+	User  string `json:"user"`
+	Text  string `json:"text"`
+	Ts    string `json:"ts"`
+	BotID string `json:"bot_id"`
+}
+
+type slackHistoryResponse struct {
+	OK       bool                  `json:"ok"`
+	Error    string                `json:"error"`
+	Messages []slackHistoryMessage `json:"messages"`
+}
+
+// nextEvent polls conversations.history for messages newer than lastTS and
+// returns the oldest unseen one (user ID, message text). conversations.history
+// returns newest-first, so a multi-message burst is drained oldest-first
+// across repeated calls. An empty result (no new messages, or a transport/
+// API error) backs off briefly instead of spinning.
+func (b *SlackBackend) nextEvent() (userID string, text string) {
+	time.Sleep(b.pollInterval) // Slack has no long-poll equivalent to back this off server-side
+
+	req, err := http.NewRequest(http.MethodGet, b.apiBase+"/conversations.history", nil)
+	if err != nil {
+		return "", ""
+	}
+	q := req.URL.Query()
+	q.Set("channel", b.Channel)
+	q.Set("oldest", b.lastTS)
+	q.Set("limit", "10")
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Authorization", "Bearer "+b.BotToken)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", ""
+	}
+	defer resp.Body.Close()
+
+	var parsed slackHistoryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil || !parsed.OK || len(parsed.Messages) == 0 {
+		return "", ""
+	}
+
+	msg := parsed.Messages[len(parsed.Messages)-1]
+	b.lastTS = msg.Ts
+	if msg.BotID != "" {
+		return "", ""
+	}
+	return msg.User, msg.Text
+}
+
+type slackReply struct {
+	backend *SlackBackend
+}
+
+func (r *slackReply) Text(message string) error {
+	body, err := jsonBody(map[string]string{
+		"channel": r.backend.Channel,
+		"text":    message,
+	})
+	if err != nil {
+		return err
+	}
+	return r.backend.post("https://slack.com/api/chat.postMessage", "application/json", body)
+}
+
+func (r *slackReply) Photo(path string, caption string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("channels", r.backend.Channel); err != nil {
+		return err
+	}
+	if err := writer.WriteField("initial_comment", caption); err != nil {
+		return err
+	}
+	part, err := writer.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return r.backend.post("https://slack.com/api/files.upload", writer.FormDataContentType(), &buf)
+}
+
+// jsonBody marshals v into a Reader suitable for an HTTP request body.
+func jsonBody(v interface{}) (io.Reader, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// post issues an authenticated POST against the Slack Web API and treats
+// any non-2xx status as an error.
+func (b *SlackBackend) post(url, contentType string, body io.Reader) error {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.BotToken)
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack %s: unexpected status %s", url, resp.Status)
+	}
+	return nil
+}
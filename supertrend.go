@@ -0,0 +1,205 @@
+// supertrend.go
+// Supertrend + ATR breakout strategy, alongside GenerateSignal's fixed
+// momentum/RSI/breakout thresholds. Computes Wilder's ATR, derives the
+// classic Supertrend bands and flip logic, and confirms flips with a
+// fast/slow DEMA cross and a linear-regression slope filter to stay out of
+// chop.
+
+package main
+
+import "time"
+
+// =============================================================================
+// SUPERTREND INDICATOR
+// =============================================================================
+
+// Supertrend is the classic ATR-band trend-following indicator: the basic
+// upper/lower bands tighten in the direction of the prevailing trend and
+// only widen on a flip, with direction flipping when close crosses the
+// opposing band.
+type Supertrend struct {
+	Window     int
+	Multiplier float64
+
+	atr *ATR
+	buffer
+	trendBuffer buffer // +1 uptrend, -1 downtrend, one entry per bar
+
+	upperBand, lowerBand float64
+	prevClose            float64
+	trend                int
+	initialized          bool
+}
+
+func NewSupertrend(window int, multiplier float64) *Supertrend {
+	return &Supertrend{Window: window, Multiplier: multiplier, atr: &ATR{Window: window}}
+}
+
+// PushK advances the indicator by one bar.
+func (s *Supertrend) PushK(bar Bar) {
+	s.atr.PushK(bar)
+	atr := s.atr.Last(0)
+
+	mid := (bar.High + bar.Low) / 2
+	basicUpper := mid + s.Multiplier*atr
+	basicLower := mid - s.Multiplier*atr
+
+	if !s.initialized {
+		s.upperBand, s.lowerBand = basicUpper, basicLower
+		s.trend = 1
+		s.prevClose = bar.Close
+		s.initialized = true
+		s.push(s.lowerBand)
+		s.trendBuffer.push(1)
+		return
+	}
+
+	// The band only moves toward price (tightens); it only jumps back out
+	// to the basic band once price has closed through it (a flip).
+	if basicUpper < s.upperBand || s.prevClose > s.upperBand {
+		s.upperBand = basicUpper
+	}
+	if basicLower > s.lowerBand || s.prevClose < s.lowerBand {
+		s.lowerBand = basicLower
+	}
+
+	switch s.trend {
+	case 1:
+		if bar.Close < s.lowerBand {
+			s.trend = -1
+		}
+	default:
+		if bar.Close > s.upperBand {
+			s.trend = 1
+		}
+	}
+	s.prevClose = bar.Close
+
+	if s.trend == 1 {
+		s.push(s.lowerBand)
+	} else {
+		s.push(s.upperBand)
+	}
+	s.trendBuffer.push(float64(s.trend))
+}
+
+// Line is the Supertrend value itself (the active band).
+func (s *Supertrend) Line() Series { return &s.buffer }
+
+// Direction is +1 for uptrend, -1 for downtrend, one value per bar.
+func (s *Supertrend) Direction() Series { return &s.trendBuffer }
+
+// =============================================================================
+// SUPERTREND STRATEGY
+// =============================================================================
+
+// SupertrendStrategy trades Supertrend flips, confirmed by a fast/slow DEMA
+// cross and gated by a linear-regression slope filter so entries are
+// blocked in a chop where the flip and the DEMA cross disagree. Exits are
+// enforced the same way as any other strategy's positions, through
+// buildExitMethods/checkExits in the backtester and RunTradingSystem.
+type SupertrendStrategy struct {
+	Window                 int
+	Multiplier             float64
+	FastDEMAWindow         int
+	SlowDEMAWindow         int
+	LinearRegressionWindow int
+
+	supertrend *Supertrend
+	fastDEMA   *DEMA
+	slowDEMA   *DEMA
+	closes     buffer
+}
+
+// newConfiguredSupertrendStrategy builds a SupertrendStrategy from Config,
+// applying the classic 10/3.0 Supertrend and 9/21/20 DEMA/regression
+// defaults to any zero-valued fields, same pattern as signalThresholds.
+func newConfiguredSupertrendStrategy(config Config) *SupertrendStrategy {
+	window := config.SupertrendWindow
+	if window == 0 {
+		window = 10
+	}
+	multiplier := config.SupertrendMultiplier
+	if multiplier == 0 {
+		multiplier = 3.0
+	}
+	fastDEMAWindow := config.FastDEMAWindow
+	if fastDEMAWindow == 0 {
+		fastDEMAWindow = 9
+	}
+	slowDEMAWindow := config.SlowDEMAWindow
+	if slowDEMAWindow == 0 {
+		slowDEMAWindow = 21
+	}
+	linRegWindow := config.LinearRegressionWindow
+	if linRegWindow == 0 {
+		linRegWindow = 20
+	}
+
+	return NewSupertrendStrategy(window, multiplier, fastDEMAWindow, slowDEMAWindow, linRegWindow)
+}
+
+func NewSupertrendStrategy(window int, multiplier float64, fastDEMAWindow, slowDEMAWindow, linRegWindow int) *SupertrendStrategy {
+	return &SupertrendStrategy{
+		Window:                 window,
+		Multiplier:             multiplier,
+		FastDEMAWindow:         fastDEMAWindow,
+		SlowDEMAWindow:         slowDEMAWindow,
+		LinearRegressionWindow: linRegWindow,
+		supertrend:             NewSupertrend(window, multiplier),
+		fastDEMA:               NewDEMA(fastDEMAWindow),
+		slowDEMA:               NewDEMA(slowDEMAWindow),
+	}
+}
+
+// PushK updates the Supertrend, DEMA, and close-price state for one bar.
+func (s *SupertrendStrategy) PushK(bar Bar) {
+	s.supertrend.PushK(bar)
+	s.fastDEMA.Update(bar.Close)
+	s.slowDEMA.Update(bar.Close)
+	s.closes.push(bar.Close)
+}
+
+// GenerateSignal produces a Signal compatible with the existing pipeline.
+// Unlike the momentum/RSI/breakout engine's GenerateSignal, this strategy is
+// binary: it only fires on the bar a Supertrend flip happens, and only if
+// the DEMA cross and regression slope both agree with the new trend
+// direction, so every signal it produces carries Confidence 1.0.
+// minConfidence is accepted for interface symmetry with the other engine
+// but has no effect here, since there's nothing between 0 and 1 to
+// threshold against.
+func (s *SupertrendStrategy) GenerateSignal(symbol string, minConfidence float64) *Signal {
+	if s.supertrend.Direction().Length() < 2 {
+		return nil
+	}
+
+	trend := s.supertrend.Direction().Last(0)
+	prevTrend := s.supertrend.Direction().Last(1)
+	if trend == prevTrend {
+		return nil // only trade the flip itself
+	}
+
+	slope := LinearRegressionSlope(&s.closes, s.LinearRegressionWindow)
+	demaBullish := s.fastDEMA.Last(0) > s.slowDEMA.Last(0)
+
+	var direction string
+	switch {
+	case trend == 1 && demaBullish && slope > 0:
+		direction = "LONG"
+	case trend == -1 && !demaBullish && slope < 0:
+		direction = "SHORT"
+	default:
+		// Chop filter: the flip alone isn't enough; the DEMA cross and
+		// slope must both agree with the new trend direction.
+		return nil
+	}
+
+	return &Signal{
+		Symbol:     symbol,
+		Direction:  direction,
+		Confidence: 1.0,
+		Strategy:   "supertrend",
+		Price:      s.closes.Last(0),
+		Timestamp:  time.Now(),
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import "testing"
+
+// TestATRWildersSmoothing checks the Wilder's-smoothing recurrence directly:
+// after the seed bar, each subsequent value is (prevATR*(n-1)+tr)/n.
+func TestATRWildersSmoothing(t *testing.T) {
+	a := &ATR{Window: 3}
+	a.PushK(Bar{High: 110, Low: 100, Close: 105}) // seed: tr = 10
+	if a.Last(0) != 10 {
+		t.Fatalf("expected seed ATR of 10, got %v", a.Last(0))
+	}
+
+	a.PushK(Bar{High: 112, Low: 106, Close: 108}) // tr = max(6, |112-105|=7, |106-105|=1) = 7
+	want := (10*2 + 7) / 3.0
+	if a.Last(0) != want {
+		t.Fatalf("expected Wilder's smoothed ATR %v, got %v", want, a.Last(0))
+	}
+}
+
+// TestSupertrendFlipsDirectionOnCloseThroughBand drives a clear downtrend
+// into a clean breakout and checks the Supertrend flips from downtrend to
+// uptrend only once close actually closes above the upper band.
+func TestSupertrendFlipsDirectionOnCloseThroughBand(t *testing.T) {
+	s := NewSupertrend(3, 1.0)
+
+	bars := []Bar{
+		{High: 100, Low: 98, Close: 99},
+		{High: 98, Low: 96, Close: 97},
+		{High: 96, Low: 94, Close: 95},
+		{High: 94, Low: 92, Close: 93}, // still downtrending
+	}
+	for _, bar := range bars {
+		s.PushK(bar)
+	}
+	if s.Direction().Last(0) != -1 {
+		t.Fatalf("expected a downtrend after a run of lower highs/lows, got %v", s.Direction().Last(0))
+	}
+
+	// A sharp rally that closes back above the upper band should flip the
+	// trend to up.
+	s.PushK(Bar{High: 140, Low: 110, Close: 135})
+	if s.Direction().Last(0) != 1 {
+		t.Fatalf("expected the trend to flip to up on a close through the upper band, got %v", s.Direction().Last(0))
+	}
+}
+
+// TestSupertrendStrategyGenerateSignalRequiresDEMAAndSlopeAgreement checks
+// the chop filter: a small enough bounce can flip the (tight-banded)
+// Supertrend trend to up on its own, but a genuine downtrend's slower DEMA
+// and regression slope won't have caught up yet, and the strategy must not
+// signal on the flip alone.
+func TestSupertrendStrategyGenerateSignalRequiresDEMAAndSlopeAgreement(t *testing.T) {
+	strategy := NewSupertrendStrategy(3, 0.05, 2, 6, 5)
+
+	downtrend := []Bar{
+		{High: 100, Low: 98, Close: 99},
+		{High: 98, Low: 96, Close: 97},
+		{High: 96, Low: 94, Close: 95},
+		{High: 94, Low: 92, Close: 93},
+		{High: 92, Low: 90, Close: 91},
+	}
+	for _, bar := range downtrend {
+		strategy.PushK(bar)
+		strategy.GenerateSignal("TEST", 0)
+	}
+
+	bounce := Bar{High: 92, Low: 90, Close: 91.5}
+	strategy.PushK(bounce)
+	if strategy.supertrend.Direction().Last(0) != 1 {
+		t.Fatalf("expected the small bounce to flip the tight-banded Supertrend to up")
+	}
+	if sig := strategy.GenerateSignal("TEST", 0); sig != nil {
+		t.Fatalf("expected no signal when the flip isn't confirmed by both DEMA cross and slope, got %+v", sig)
+	}
+}
+
+// TestSupertrendStrategyGenerateSignalFiresOnAgreeingFlip builds a clean,
+// one-directional breakout where the flip, DEMA cross, and slope all agree,
+// and checks a LONG signal is produced with the entry price on the flip bar.
+func TestSupertrendStrategyGenerateSignalFiresOnAgreeingFlip(t *testing.T) {
+	strategy := NewSupertrendStrategy(3, 1.0, 2, 4, 3)
+
+	downtrend := []Bar{
+		{High: 100, Low: 98, Close: 99},
+		{High: 98, Low: 96, Close: 97},
+		{High: 96, Low: 94, Close: 95},
+		{High: 94, Low: 92, Close: 93},
+	}
+	for _, bar := range downtrend {
+		strategy.PushK(bar)
+		strategy.GenerateSignal("TEST", 0)
+	}
+
+	flip := Bar{High: 140, Low: 110, Close: 135}
+	strategy.PushK(flip)
+	sig := strategy.GenerateSignal("TEST", 0)
+	if sig == nil {
+		t.Fatalf("expected a LONG signal on a flip confirmed by both DEMA cross and slope")
+	}
+	if sig.Direction != "LONG" {
+		t.Fatalf("expected LONG, got %s", sig.Direction)
+	}
+	if sig.Price != flip.Close {
+		t.Fatalf("expected signal price to be the flip bar's close %v, got %v", flip.Close, sig.Price)
+	}
+	if sig.Confidence != 1.0 {
+		t.Fatalf("expected a confirmed flip to always carry Confidence 1.0, got %v", sig.Confidence)
+	}
+}
+
+// TestSupertrendStrategyGenerateSignalMinConfidenceIsANoOp documents that,
+// unlike the momentum/RSI/breakout engine, minConfidence has no effect here:
+// GenerateSignal is binary (a confirmed flip or nil), so there's nothing
+// between 0 and 1 for a threshold to filter.
+func TestSupertrendStrategyGenerateSignalMinConfidenceIsANoOp(t *testing.T) {
+	strategy := NewSupertrendStrategy(3, 1.0, 2, 4, 3)
+
+	downtrend := []Bar{
+		{High: 100, Low: 98, Close: 99},
+		{High: 98, Low: 96, Close: 97},
+		{High: 96, Low: 94, Close: 95},
+		{High: 94, Low: 92, Close: 93},
+	}
+	for _, bar := range downtrend {
+		strategy.PushK(bar)
+		strategy.GenerateSignal("TEST", 0.9)
+	}
+
+	flip := Bar{High: 140, Low: 110, Close: 135}
+	strategy.PushK(flip)
+	if sig := strategy.GenerateSignal("TEST", 0.9); sig == nil {
+		t.Fatalf("expected a confirmed flip to signal regardless of minConfidence")
+	}
+}
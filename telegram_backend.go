@@ -0,0 +1,226 @@
+// telegram_backend.go
+// Telegram bot backend for Interact. Only the chat that proves ownership
+// via a one-time code printed to the console at startup (/auth <otp>) may
+// run mutating commands like /close, /pause, and /resume.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TelegramBackend polls the Telegram Bot API for updates and dispatches
+// them to Interact. Token is the bot token; ownerChatID, once authorized
+// via the OTP flow, is the only chat allowed to run mutating commands.
+type TelegramBackend struct {
+	Token string
+
+	client      *http.Client
+	offset      int64
+	otp         string
+	ownerChatID int64
+	authorized  bool
+	stop        chan struct{}
+}
+
+func NewTelegramBackend(token string) (*TelegramBackend, error) {
+	otp, err := generateOTP()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("[telegram] owner auth code: %s (send \"/auth %s\" to the bot to authorize mutating commands)\n", otp, otp)
+
+	return &TelegramBackend{
+		Token:  token,
+		client: &http.Client{Timeout: 35 * time.Second},
+		otp:    otp,
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+func (b *TelegramBackend) Start(dispatch func(cmd string, args []string, reply Reply)) error {
+	go b.poll(dispatch)
+	return nil
+}
+
+func (b *TelegramBackend) Stop() error {
+	close(b.stop)
+	return nil
+}
+
+// poll long-polls getUpdates and turns each incoming message into a
+// dispatch call. nextMessage itself blocks for up to its long-poll timeout
+// (or briefly sleeps on a transport or API error), so this loop never
+// busy-spins.
+func (b *TelegramBackend) poll(dispatch func(cmd string, args []string, reply Reply)) {
+	for {
+		select {
+		case <-b.stop:
+			return
+		default:
+		}
+
+		chatID, text := b.nextMessage()
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+			continue
+		}
+		cmd := strings.TrimPrefix(fields[0], "/")
+		args := fields[1:]
+
+		reply := &telegramReply{backend: b, chatID: chatID}
+
+		if cmd == "auth" {
+			b.handleAuth(chatID, args, reply)
+			continue
+		}
+
+		if mutatingCommand(cmd) && (!b.authorized || chatID != b.ownerChatID) {
+			reply.Text("unauthorized: send /auth <code> first")
+			continue
+		}
+
+		dispatch(cmd, args, reply)
+	}
+}
+
+func (b *TelegramBackend) handleAuth(chatID int64, args []string, reply Reply) {
+	if len(args) != 1 || args[0] != b.otp {
+		reply.Text("invalid code")
+		return
+	}
+	b.ownerChatID = chatID
+	b.authorized = true
+	reply.Text("authorized")
+}
+
+type tgUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+type tgGetUpdatesResponse struct {
+	OK     bool       `json:"ok"`
+	Result []tgUpdate `json:"result"`
+}
+
+// nextMessage long-polls GET .../getUpdates?offset=...&timeout=30 and
+// returns the first new message, if any. The 30s server-side long-poll (or
+// a short sleep after a transport or API error) is what keeps this from
+// spinning.
+func (b *TelegramBackend) nextMessage() (chatID int64, text string) {
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?offset=%d&timeout=30", b.Token, b.offset)
+	resp, err := b.client.Get(endpoint)
+	if err != nil {
+		time.Sleep(2 * time.Second)
+		return 0, ""
+	}
+	defer resp.Body.Close()
+
+	var parsed tgGetUpdatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil || !parsed.OK {
+		time.Sleep(2 * time.Second)
+		return 0, ""
+	}
+	if len(parsed.Result) == 0 {
+		return 0, ""
+	}
+
+	update := parsed.Result[0]
+	b.offset = update.UpdateID + 1
+	if update.Message == nil {
+		return 0, ""
+	}
+	return update.Message.Chat.ID, update.Message.Text
+}
+
+// mutatingCommand reports whether cmd changes state rather than just
+// reporting it, matching the `mutating` flag RegisterBuiltinCommands passes
+// for each built-in command.
+func mutatingCommand(cmd string) bool {
+	switch cmd {
+	case "close", "pause", "resume":
+		return true
+	default:
+		return false
+	}
+}
+
+type telegramReply struct {
+	backend *TelegramBackend
+	chatID  int64
+}
+
+func (r *telegramReply) Text(message string) error {
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", r.backend.Token)
+	resp, err := r.backend.client.PostForm(endpoint, url.Values{
+		"chat_id": {strconv.FormatInt(r.chatID, 10)},
+		"text":    {message},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram sendMessage: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (r *telegramReply) Photo(path string, caption string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("chat_id", strconv.FormatInt(r.chatID, 10)); err != nil {
+		return err
+	}
+	if err := writer.WriteField("caption", caption); err != nil {
+		return err
+	}
+	part, err := writer.CreateFormFile("photo", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendPhoto", r.backend.Token)
+	resp, err := r.backend.client.Post(endpoint, writer.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram sendPhoto: unexpected status %s", resp.Status)
+	}
+	return nil
+}